@@ -3,18 +3,38 @@ package ctxlock
 
 import (
 	"context"
+	"errors"
 	"sync/atomic"
 )
 
 type (
+	// Token is a fencing token, which increases every time a lock is acquired.
+	Token uint64
+
+	// IBackend is interface for lock backend.
+	IBackend interface {
+		// Acquire tries to lock and returns fencing token when it succeed.
+		// If ctx is canceled, it must give up and return error.
+		Acquire(ctx context.Context) (Token, error)
+
+		// Release unlocks the lock acquired with the token.
+		Release(token Token) error
+	}
+
+	// localBackend implements IBackend inside a process.
+	localBackend struct {
+		lck chan struct{}
+		token atomic.Uint64
+	}
+
 	// Lock implements ordinary exclusive lock.
 	Lock struct {
-		lck chan struct{}
+		backend IBackend
 	}
 
 	// SharableLock implements exclusive lock for writer and shared lock for reader.
 	SharableLock struct {
-		lock *Lock
+		lock *localBackend
 		want *atomic.Int32
 		add chan struct{}
 		done chan struct{}
@@ -37,43 +57,99 @@ func onceFunc(f func()) func() {
 }
 
 
+var (
+	ErrNotLocked = errors.New("Lock is not held with the token.")
+)
+
+
+// newLocalBackend creates a new localBackend and returns the pointer to it.
+func newLocalBackend() *localBackend {
+	return &localBackend{
+		lck: make(chan struct{}, 1),
+	}
+}
+
+// Acquire tries to lock and returns fencing token when it succeed.
+func (b *localBackend) Acquire(ctx context.Context) (Token, error) {
+	select {
+	case b.lck <- struct{}{}:
+		return b.next(), nil
+	case <- ctx.Done():
+		return 0, context.Cause(ctx)
+	}
+}
+
+// next issues new fencing token. It must be called during lock is held.
+func (b *localBackend) next() Token {
+	return Token(b.token.Add(1))
+}
+
+// Release unlocks the lock.
+func (b *localBackend) Release(token Token) error {
+	if Token(b.token.Load()) != token {
+		return ErrNotLocked
+	}
+
+	select {
+	case <- b.lck:
+		return nil
+	default:
+		return ErrNotLocked
+	}
+}
+
+// unlockFunc returns unlock function.
+// It is safe to call the returned function multiple time.
+func (b *localBackend) unlockFunc() UnlockFunc {
+	return onceFunc(func(){ <-b.lck })
+}
+
+
 // NewLock creates a new Lock and returns the pointer to it.
 func NewLock() *Lock {
+	return NewLockWithBackend(newLocalBackend())
+}
+
+// NewLockWithBackend creates a new Lock with backend and returns the pointer to it.
+// Backend can lock across processes, e.g. file lock.
+func NewLockWithBackend(backend IBackend) *Lock {
 	return &Lock{
-		lck: make(chan struct{}, 1),
+		backend: backend,
 	}
 }
 
 // Lock tries to lock and returns unlock function when it succeed.
 // If ctx is canceled, lock is canceled and context.Cause(ctx) error is returned.
 func (L *Lock) Lock(ctx context.Context) (UnlockFunc, error) {
+	unlock, _, err := L.LockWithToken(ctx)
+	return unlock, err
+}
+
+// LockWithToken tries to lock and returns unlock function and fencing token
+// when it succeed.
+// If ctx is canceled, lock is canceled and context.Cause(ctx) error is returned.
+// Error from backend release is ignored by the unlock function.
+func (L *Lock) LockWithToken(ctx context.Context) (UnlockFunc, Token, error) {
 	// If ctx has already been canceled, we don't try to lock at all.
 	select {
 	case <- ctx.Done():
-		return nil, context.Cause(ctx)
+		return nil, 0, context.Cause(ctx)
 	default:
 	}
 
-	select {
-	case L.lck <- struct{}{}:
-		return L.unlockFunc(), nil
-	case <- ctx.Done():
-		return nil, context.Cause(ctx)
+	token, err := L.backend.Acquire(ctx)
+	if err != nil {
+		return nil, 0, err
 	}
-}
-
 
-// unlockFunc returns unlock function.
-// It is safe to call the returned function multiple time.
-func (L *Lock) unlockFunc() UnlockFunc {
-	return onceFunc(func(){ <-L.lck })
+	return onceFunc(func(){ L.backend.Release(token) }), token, nil
 }
 
 // NewSharableLock creates a new SharableLock and returns the pointer to it.
 func NewSharableLock() *SharableLock {
 	var want atomic.Int32
 	return &SharableLock{
-		lock: NewLock(),
+		lock: newLocalBackend(),
 		want: &want,
 		add: make(chan struct{}),
 		done: make(chan struct{}),
@@ -149,7 +225,12 @@ func (L *SharableLock) ExclusiveLock(ctx context.Context) (UnlockFunc, error) {
 	L.want.Add(1)
 	defer L.want.Add(-1)
 
-	return L.lock.Lock(ctx)
+	token, err := L.lock.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return onceFunc(func(){ L.lock.Release(token) }), nil
 }
 
 // UnlockOnCancel schedules to call unlock when ctx cancels.
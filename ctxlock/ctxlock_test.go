@@ -181,6 +181,30 @@ func TestUnlock(t *testing.T){
 	unlock()
 }
 
+func TestLockWithToken(t *testing.T){
+	L := NewLock()
+
+	unlock, token1, err := L.LockWithToken(context.Background())
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	unlock()
+
+	unlock, token2, err := L.LockWithToken(context.Background())
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	unlock()
+
+	// Fencing token must increase.
+	if token2 <= token1 {
+		t.Errorf("Token must increase: %d -> %d\n", token1, token2)
+		return
+	}
+}
+
 type (
 	NaiveLock struct {
 		mu sync.Mutex
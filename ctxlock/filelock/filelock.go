@@ -0,0 +1,135 @@
+// Package filelock provides file based lock backend for ctxlock.
+// The lock is shared across processes on the same host through flock(2).
+package filelock
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ymd-h/go/ctxlock"
+)
+
+type (
+	// Backend implements ctxlock.IBackend with file lock.
+	Backend struct {
+		path string
+		interval time.Duration
+		mu sync.Mutex
+		files map[ctxlock.Token]*os.File
+	}
+)
+
+var (
+	ErrNotSupported = errors.New("File lock is not supported on this platform.")
+	errWouldBlock = errors.New("File is locked by others.")
+)
+
+const (
+	DefaultInterval = 10 * time.Millisecond
+)
+
+
+// New creates a new Backend for path and returns the pointer to it.
+// The file is created if it does not exist.
+// The file is also used to store the last fencing token.
+func New(path string) *Backend {
+	return NewWithInterval(path, DefaultInterval)
+}
+
+// NewWithInterval creates a new Backend for path and returns the pointer to it.
+// Since flock(2) cannot be canceled, the lock is tried every interval.
+func NewWithInterval(path string, interval time.Duration) *Backend {
+	return &Backend{
+		path: path,
+		interval: interval,
+		files: map[ctxlock.Token]*os.File{},
+	}
+}
+
+// Acquire tries to lock and returns fencing token when it succeed.
+// If ctx is canceled, lock is canceled and context.Cause(ctx) error is returned.
+func (b *Backend) Acquire(ctx context.Context) (ctxlock.Token, error) {
+	f, err := os.OpenFile(b.path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("Fail to Open Lock File: %w", err)
+	}
+
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		err = tryLock(f)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errWouldBlock) {
+			f.Close()
+			return 0, fmt.Errorf("Fail to Lock File: %w", err)
+		}
+
+		select {
+		case <- ctx.Done():
+			f.Close()
+			return 0, context.Cause(ctx)
+		case <- ticker.C:
+		}
+	}
+
+	token, err := nextToken(f)
+	if err != nil {
+		unlock(f)
+		f.Close()
+		return 0, fmt.Errorf("Fail to Issue Token: %w", err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.files[token] = f
+
+	return token, nil
+}
+
+// Release unlocks the lock acquired with the token.
+func (b *Backend) Release(token ctxlock.Token) error {
+	b.mu.Lock()
+	f, ok := b.files[token]
+	delete(b.files, token)
+	b.mu.Unlock()
+
+	if !ok {
+		return ctxlock.ErrNotLocked
+	}
+	defer f.Close()
+
+	if err := unlock(f); err != nil {
+		return fmt.Errorf("Fail to Unlock File: %w", err)
+	}
+	return nil
+}
+
+// nextToken reads the last token from locked file,
+// then writes and returns the incremented one.
+func nextToken(f *os.File) (ctxlock.Token, error) {
+	var buf [8]byte
+	if _, err := f.ReadAt(buf[:], 0); err != nil && err != io.EOF {
+		return 0, err
+	}
+
+	token := ctxlock.Token(binary.BigEndian.Uint64(buf[:]) + 1)
+	binary.BigEndian.PutUint64(buf[:], uint64(token))
+
+	if _, err := f.WriteAt(buf[:], 0); err != nil {
+		return 0, err
+	}
+	if err := f.Sync(); err != nil {
+		return 0, err
+	}
+
+	return token, nil
+}
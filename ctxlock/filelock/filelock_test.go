@@ -0,0 +1,80 @@
+package filelock
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ymd-h/go/ctxlock"
+)
+
+func TestBackend(t *testing.T){
+	path := filepath.Join(t.TempDir(), "lock")
+	L1 := ctxlock.NewLockWithBackend(New(path))
+	L2 := ctxlock.NewLockWithBackend(New(path))
+	dt := 50 * time.Millisecond
+
+	// Lock
+	// -> OK
+	unlock, token1, err := L1.LockWithToken(context.Background())
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	// Lock from other backend when it is already locked.
+	// -> error
+	ctx, cancel := context.WithTimeout(context.Background(), dt)
+	defer cancel()
+	_, errT := L2.Lock(ctx)
+	if errT == nil {
+		t.Errorf("Must Fail\n")
+		return
+	}
+
+	// Lock when it unlock during waiting.
+	// -> OK
+	go func(){
+		<- time.After(dt)
+		unlock()
+	}()
+
+	unlock2, token2, err := L2.LockWithToken(context.Background())
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	unlock2()
+
+	// Fencing token must increase.
+	if token2 <= token1 {
+		t.Errorf("Token must increase: %d -> %d\n", token1, token2)
+		return
+	}
+}
+
+func TestRelease(t *testing.T){
+	b := New(filepath.Join(t.TempDir(), "lock"))
+
+	token, err := b.Acquire(context.Background())
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	if err := b.Release(token + 1); err != ctxlock.ErrNotLocked {
+		t.Errorf("Must be ErrNotLocked: %v\n", err)
+		return
+	}
+
+	if err := b.Release(token); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	if err := b.Release(token); err != ctxlock.ErrNotLocked {
+		t.Errorf("Must be ErrNotLocked: %v\n", err)
+		return
+	}
+}
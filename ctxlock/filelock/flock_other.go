@@ -0,0 +1,17 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package filelock
+
+import (
+	"os"
+)
+
+// tryLock is not supported.
+func tryLock(f *os.File) error {
+	return ErrNotSupported
+}
+
+// unlock is not supported.
+func unlock(f *os.File) error {
+	return ErrNotSupported
+}
@@ -0,0 +1,118 @@
+package ctxlock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+type (
+	// Lease is a lock holding which is released automatically
+	// when it is not renewed until its deadline.
+	Lease struct {
+		mu sync.Mutex
+		deadline time.Time
+		timer *time.Timer
+		unlock UnlockFunc
+		lost chan struct{}
+		released bool
+	}
+)
+
+var (
+	ErrLeaseLost = errors.New("Lease has already been lost.")
+	ErrInvalidTTL = errors.New("TTL must be positive.")
+)
+
+
+// newLease creates a new Lease for already acquired lock.
+func newLease(unlock UnlockFunc, ttl time.Duration) *Lease {
+	l := &Lease{
+		deadline: time.Now().Add(ttl),
+		unlock: unlock,
+		lost: make(chan struct{}),
+	}
+	l.timer = time.AfterFunc(ttl, l.expire)
+	return l
+}
+
+// expire is called by timer.
+// If the lease has been renewed, timer is rescheduled to the new deadline.
+func (l *Lease) expire(){
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.released {
+		return
+	}
+
+	if dt := time.Until(l.deadline); dt > 0 {
+		l.timer.Reset(dt)
+		return
+	}
+
+	l.released = true
+	close(l.lost)
+	l.unlock()
+}
+
+// Renew extends the lease by ttl from now.
+// If the lease has already been lost or unlocked, ErrLeaseLost is returned.
+func (l *Lease) Renew(ttl time.Duration) error {
+	if ttl <= 0 {
+		return ErrInvalidTTL
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.released {
+		return ErrLeaseLost
+	}
+
+	deadline := time.Now().Add(ttl)
+	if deadline.Before(l.deadline) {
+		// Timer is waiting for later deadline, so that we reschedule it.
+		l.timer.Reset(ttl)
+	}
+	l.deadline = deadline
+	return nil
+}
+
+// Unlock releases the lease.
+// It is safe to call multiple time, and also after the lease is lost.
+func (l *Lease) Unlock(){
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.released {
+		return
+	}
+
+	l.released = true
+	l.timer.Stop()
+	l.unlock()
+}
+
+// Lost returns a channel, which will be closed when the lease expires.
+// The channel is not closed by Unlock.
+func (l *Lease) Lost() <- chan struct{} {
+	return l.lost
+}
+
+// LockWithLease tries to lock and returns Lease when it succeed.
+// The lock is released automatically after ttl unless Lease.Renew is called.
+// If ctx is canceled, lock is canceled and context.Cause(ctx) error is returned.
+func (L *Lock) LockWithLease(ctx context.Context, ttl time.Duration) (*Lease, error) {
+	if ttl <= 0 {
+		return nil, ErrInvalidTTL
+	}
+
+	unlock, err := L.Lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return newLease(unlock, ttl), nil
+}
@@ -0,0 +1,125 @@
+package ctxlock
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLease(t *testing.T){
+	L := NewLock()
+	ttl := 50 * time.Millisecond
+
+	// LockWithLease
+	// -> OK
+	lease, err := L.LockWithLease(context.Background(), ttl)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	// Lock during lease
+	// -> error
+	ctx, cancel := newTimeout(time.Duration(100000))
+	defer cancel()
+	_, errT := L.Lock(ctx)
+	if errT == nil {
+		t.Errorf("Must Fail\n")
+		return
+	}
+
+	// Lock after lease expired
+	// -> OK
+	unlock, err := L.Lock(context.Background())
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	select {
+	case <- lease.Lost():
+	default:
+		t.Errorf("Lost channel must be closed\n")
+		return
+	}
+
+	// Renew after lease expired
+	// -> error
+	if err := lease.Renew(ttl); err != ErrLeaseLost {
+		t.Errorf("Must be ErrLeaseLost: %v\n", err)
+		return
+	}
+
+	// Unlock after lease expired must not release others' lock.
+	lease.Unlock()
+	ctx, cancel = newTimeout(time.Duration(100000))
+	defer cancel()
+	_, errT = L.Lock(ctx)
+	if errT == nil {
+		t.Errorf("Must Fail\n")
+		return
+	}
+	unlock()
+}
+
+func TestLeaseRenew(t *testing.T){
+	L := NewLock()
+	ttl := 50 * time.Millisecond
+
+	lease, err := L.LockWithLease(context.Background(), ttl)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	for i := 0; i < 4; i++ {
+		<- time.After(ttl / 2)
+		if err := lease.Renew(ttl); err != nil {
+			t.Errorf("Fail: %v\n", err)
+			return
+		}
+	}
+
+	select {
+	case <- lease.Lost():
+		t.Errorf("Lease must not be lost\n")
+		return
+	default:
+	}
+
+	lease.Unlock()
+	lease.Unlock()
+
+	// Lock after unlock
+	// -> OK
+	ctx, cancel := newTimeout(time.Duration(100000))
+	defer cancel()
+	unlock, err := L.Lock(ctx)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	unlock()
+
+	select {
+	case <- lease.Lost():
+		t.Errorf("Unlock must not close Lost channel\n")
+		return
+	default:
+	}
+
+	if err := lease.Renew(ttl); err != ErrLeaseLost {
+		t.Errorf("Must be ErrLeaseLost: %v\n", err)
+		return
+	}
+}
+
+func TestLeaseInvalidTTL(t *testing.T){
+	L := NewLock()
+
+	_, err := L.LockWithLease(context.Background(), 0)
+	if err != ErrInvalidTTL {
+		t.Errorf("Must be ErrInvalidTTL: %v\n", err)
+		return
+	}
+}
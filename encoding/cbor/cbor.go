@@ -0,0 +1,53 @@
+// Package encoding/cbor implements Encoder/Decoder for CBOR
+package cbor
+
+import (
+	"io"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ymd-h/go/encoding"
+)
+
+type (
+	Encoder struct {}
+	Decoder struct {}
+)
+
+const (
+	// MediaType is registered to encoding.DefaultRegistry on import.
+	MediaType = "application/cbor"
+)
+
+func init(){
+	encoding.Register(encoding.Codec{
+		MediaType: MediaType,
+		Extensions: []string{".cbor"},
+		Encoder: Encoder{},
+		Decoder: Decoder{},
+	})
+}
+
+// Encode encodes data and returns encoded io.Reader.
+func (_ Encoder) Encode(data any) (io.Reader, error) {
+	return encoding.Encode(cbor.NewEncoder, data)
+}
+
+// EncodeTo encodes data and writes it to w without intermediate buffer.
+func (_ Encoder) EncodeTo(w io.Writer, data any) error {
+	return encoding.EncodeTo(cbor.NewEncoder, w, data)
+}
+
+// Decode decodes buf io.Reader to ptr.
+func (_ Decoder) Decode(buf io.Reader, ptr any) error {
+	return encoding.Decode(cbor.NewDecoder, buf, ptr)
+}
+
+// EncodeStream creates a new StreamEncoder writing CBOR sequence to w.
+func EncodeStream(w io.Writer) *encoding.StreamEncoder {
+	return encoding.EncodeStream(cbor.NewEncoder, w)
+}
+
+// DecodeStream[T] creates a new StreamDecoder[T] reading CBOR sequence from r.
+func DecodeStream[T any](r io.Reader) *encoding.StreamDecoder[T] {
+	return encoding.DecodeStream[T](cbor.NewDecoder, r)
+}
@@ -0,0 +1,347 @@
+package encoding
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+type (
+	// ContentCoding is a transformation applied to encoded bytes,
+	// e.g. compression.
+	ContentCoding struct {
+		// Name is the token of Content-Encoding header, e.g. "gzip".
+		Name string
+
+		NewWriter func(io.Writer) (io.WriteCloser, error)
+		NewReader func(io.Reader) (io.ReadCloser, error)
+	}
+
+	// WrappedEncoder applies Codings in order to the output of Encoder.
+	WrappedEncoder struct {
+		Encoder ICodecEncoder
+		Codings []ContentCoding
+	}
+
+	// WrappedDecoder removes Codings in reverse order before Decoder.
+	WrappedDecoder struct {
+		Decoder ICodecDecoder
+		Codings []ContentCoding
+	}
+
+	// lengthPrefixWriter buffers data and writes it with length prefix on Close.
+	lengthPrefixWriter struct {
+		w io.Writer
+		buf bytes.Buffer
+	}
+
+	// multiCloser closes readers from the outermost.
+	multiCloser struct {
+		io.Reader
+		closers []io.Closer
+	}
+)
+
+var (
+	ErrUnsupportedContentEncoding = errors.New("Unsupported content encoding")
+	ErrFrameTooLarge = errors.New("Frame too large")
+
+	// Gzip compresses with gzip.
+	Gzip = ContentCoding{
+		Name: "gzip",
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		},
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+	}
+
+	// Deflate compresses with zlib format,
+	// which is called "deflate" in HTTP (RFC 9110).
+	Deflate = ContentCoding{
+		Name: "deflate",
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return zlib.NewWriter(w), nil
+		},
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			return zlib.NewReader(r)
+		},
+	}
+
+	// Zstd compresses with Zstandard.
+	Zstd = ContentCoding{
+		Name: "zstd",
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return zstd.NewWriter(w)
+		},
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			d, err := zstd.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			return d.IOReadCloser(), nil
+		},
+	}
+
+	// Base64 armors with standard base64 encoding.
+	Base64 = ContentCoding{
+		Name: "base64",
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return base64.NewEncoder(base64.StdEncoding, w), nil
+		},
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			return io.NopCloser(base64.NewDecoder(base64.StdEncoding, r)), nil
+		},
+	}
+
+	// LengthPrefix frames with 4 bytes big endian length.
+	// Since a reader reads only a single frame,
+	// concatenated frames can be decoded one by one.
+	LengthPrefix = ContentCoding{
+		Name: "x-length-prefix",
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return &lengthPrefixWriter{ w: w }, nil
+		},
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			var n uint32
+			if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+				return nil, err
+			}
+			return io.NopCloser(io.LimitReader(r, int64(n))), nil
+		},
+	}
+
+	contentCodingMu sync.RWMutex
+	contentCodings = map[string]ContentCoding{}
+)
+
+
+func init(){
+	for _, c := range []ContentCoding{ Gzip, Deflate, Zstd, Base64, LengthPrefix } {
+		RegisterContentCoding(c)
+	}
+}
+
+func (w *lengthPrefixWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *lengthPrefixWriter) Close() error {
+	if uint64(w.buf.Len()) > 0xFFFFFFFF {
+		return fmt.Errorf("%w: %d", ErrFrameTooLarge, w.buf.Len())
+	}
+
+	if err := binary.Write(w.w, binary.BigEndian, uint32(w.buf.Len())); err != nil {
+		return err
+	}
+	_, err := w.buf.WriteTo(w.w)
+	return err
+}
+
+func (m *multiCloser) Close() error {
+	var err error
+	for i := len(m.closers) - 1; i >= 0; i-- {
+		if e := m.closers[i].Close(); (e != nil) && (err == nil) {
+			err = e
+		}
+	}
+	return err
+}
+
+
+// RegisterContentCoding registers c by its Name.
+// Gzip, Deflate, Zstd, Base64 and LengthPrefix are registered by default.
+func RegisterContentCoding(c ContentCoding) {
+	contentCodingMu.Lock()
+	defer contentCodingMu.Unlock()
+
+	contentCodings[strings.ToLower(c.Name)] = c
+}
+
+// LookupContentCoding returns ContentCoding registered as name.
+func LookupContentCoding(name string) (ContentCoding, error) {
+	contentCodingMu.RLock()
+	defer contentCodingMu.RUnlock()
+
+	if c, ok := contentCodings[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return c, nil
+	}
+	return ContentCoding{}, fmt.Errorf("%w: %s", ErrUnsupportedContentEncoding, name)
+}
+
+// ParseContentEncoding parses Content-Encoding header
+// and returns ContentCoding in the order they were applied.
+// "identity" is ignored.
+func ParseContentEncoding(contentEncoding string) ([]ContentCoding, error) {
+	codings := make([]ContentCoding, 0)
+	for _, name := range strings.Split(contentEncoding, ",") {
+		name = strings.TrimSpace(name)
+		if (name == "") || strings.EqualFold(name, "identity") {
+			continue
+		}
+
+		c, err := LookupContentCoding(name)
+		if err != nil {
+			return nil, err
+		}
+		codings = append(codings, c)
+	}
+	return codings, nil
+}
+
+// FormatContentEncoding returns Content-Encoding header value of codings.
+func FormatContentEncoding(codings []ContentCoding) string {
+	names := make([]string, 0, len(codings))
+	for _, c := range codings {
+		names = append(names, c.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// DecodeContent returns io.ReadCloser which removes codings of
+// contentEncoding header from r.
+// Closing it doesn't close r.
+func DecodeContent(r io.Reader, contentEncoding string) (io.ReadCloser, error) {
+	codings, err := ParseContentEncoding(contentEncoding)
+	if err != nil {
+		return nil, err
+	}
+	return newContentReader(r, codings)
+}
+
+// newContentReader removes codings in reverse order.
+func newContentReader(r io.Reader, codings []ContentCoding) (io.ReadCloser, error) {
+	m := &multiCloser{ Reader: r, closers: make([]io.Closer, 0, len(codings)) }
+	for i := len(codings) - 1; i >= 0; i-- {
+		rc, err := codings[i].NewReader(m.Reader)
+		if err != nil {
+			m.Close()
+			return nil, fmt.Errorf("Fail to Decode %s: %w", codings[i].Name, err)
+		}
+		m.Reader = rc
+		m.closers = append(m.closers, rc)
+	}
+	return m, nil
+}
+
+
+// WrapEncoder creates a new WrappedEncoder, which applies codings in order.
+func WrapEncoder(enc ICodecEncoder, codings ...ContentCoding) WrappedEncoder {
+	return WrappedEncoder{ Encoder: enc, Codings: codings }
+}
+
+// WrapDecoder creates a new WrappedDecoder, which removes codings in reverse order.
+// The same codings as WrapEncoder can be passed.
+func WrapDecoder(dec ICodecDecoder, codings ...ContentCoding) WrappedDecoder {
+	return WrappedDecoder{ Decoder: dec, Codings: codings }
+}
+
+// WrapCodec returns Codec whose Encoder and Decoder are wrapped with codings.
+func WrapCodec(c Codec, codings ...ContentCoding) Codec {
+	c.Encoder = WrapEncoder(c.Encoder, codings...)
+	c.Decoder = WrapDecoder(c.Decoder, codings...)
+	return c
+}
+
+// Encode encodes data and returns encoded io.Reader.
+func (e WrappedEncoder) Encode(data any) (io.Reader, error) {
+	r, err := e.Encoder.Encode(data)
+	if (err != nil) || (r == nil) {
+		return r, err
+	}
+
+	for _, c := range e.Codings {
+		buf := bytes.NewBuffer([]byte{})
+
+		w, err := c.NewWriter(buf)
+		if err != nil {
+			return nil, fmt.Errorf("Fail to Encode %s: %w", c.Name, err)
+		}
+
+		if _, err := io.Copy(w, r); err != nil {
+			w.Close()
+			return nil, fmt.Errorf("Fail to Encode %s: %w", c.Name, err)
+		}
+
+		if err := w.Close(); err != nil {
+			return nil, fmt.Errorf("Fail to Encode %s: %w", c.Name, err)
+		}
+
+		r = buf
+	}
+
+	return r, nil
+}
+
+// EncodeTo encodes data and writes it to w through Codings.
+// If Encoder is ICodecEncoderTo, intermediate buffers are not used.
+func (e WrappedEncoder) EncodeTo(w io.Writer, data any) error {
+	if data == nil {
+		return nil
+	}
+
+	closers := make([]io.Closer, 0, len(e.Codings))
+	for i := len(e.Codings) - 1; i >= 0; i-- {
+		wc, err := e.Codings[i].NewWriter(w)
+		if err != nil {
+			return fmt.Errorf("Fail to Encode %s: %w", e.Codings[i].Name, err)
+		}
+		w = wc
+		closers = append(closers, wc)
+	}
+
+	var err error
+	if enc, ok := e.Encoder.(ICodecEncoderTo); ok {
+		err = enc.EncodeTo(w, data)
+	} else {
+		var r io.Reader
+		r, err = e.Encoder.Encode(data)
+		if (err == nil) && (r != nil) {
+			_, err = io.Copy(w, r)
+		}
+	}
+
+	// Close from the innermost to flush in order
+	for i := len(closers) - 1; i >= 0; i-- {
+		if cerr := closers[i].Close(); (cerr != nil) && (err == nil) {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// ContentEncoding returns Content-Encoding header value.
+func (e WrappedEncoder) ContentEncoding() string {
+	return FormatContentEncoding(e.Codings)
+}
+
+// Decode decodes buf io.Reader to ptr.
+func (d WrappedDecoder) Decode(buf io.Reader, ptr any) error {
+	r, err := newContentReader(buf, d.Codings)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return d.Decoder.Decode(r, ptr)
+}
+
+// ContentEncoding returns Content-Encoding header value which can be decoded.
+func (d WrappedDecoder) ContentEncoding() string {
+	return FormatContentEncoding(d.Codings)
+}
+
+// Unwrap returns the underlying Decoder.
+func (d WrappedDecoder) Unwrap() ICodecDecoder {
+	return d.Decoder
+}
@@ -0,0 +1,184 @@
+package encoding
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type (
+	stringCodec struct {}
+)
+
+func (_ stringCodec) Encode(data any) (io.Reader, error) {
+	return strings.NewReader(data.(string)), nil
+}
+
+func (_ stringCodec) Decode(r io.Reader, ptr any) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	*(ptr.(*string)) = string(b)
+	return nil
+}
+
+func TestWrap(t *testing.T){
+	data := strings.Repeat("abcde", 100)
+
+	for _, codings := range [][]ContentCoding{
+		{},
+		{ Gzip },
+		{ Deflate },
+		{ Zstd },
+		{ Base64 },
+		{ LengthPrefix },
+		{ Zstd, Base64 },
+		{ Gzip, Base64, LengthPrefix },
+	} {
+		enc := WrapEncoder(stringCodec{}, codings...)
+		dec := WrapDecoder(stringCodec{}, codings...)
+
+		r, err := enc.Encode(data)
+		if err != nil {
+			t.Errorf("Fail: %s: %v\n", enc.ContentEncoding(), err)
+			return
+		}
+
+		var s string
+		if err := dec.Decode(r, &s); err != nil {
+			t.Errorf("Fail: %s: %v\n", dec.ContentEncoding(), err)
+			return
+		}
+
+		if s != data {
+			t.Errorf("Fail: %s: %s\n", enc.ContentEncoding(), s)
+			return
+		}
+	}
+}
+
+func TestContentEncoding(t *testing.T){
+	enc := WrapEncoder(stringCodec{}, Gzip, Base64)
+	if ce := enc.ContentEncoding(); ce != "gzip, base64" {
+		t.Errorf("Fail: %s\n", ce)
+		return
+	}
+
+	r, err := enc.Encode("abc")
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	rc, err := DecodeContent(r, "identity, GZIP, base64")
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	defer rc.Close()
+
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if string(b) != "abc" {
+		t.Errorf("Fail: %s\n", string(b))
+		return
+	}
+
+	_, err = DecodeContent(r, "br")
+	if !errors.Is(err, ErrUnsupportedContentEncoding) {
+		t.Errorf("Must be ErrUnsupportedContentEncoding: %v\n", err)
+		return
+	}
+}
+
+func TestLengthPrefixFrames(t *testing.T){
+	enc := WrapEncoder(stringCodec{}, LengthPrefix)
+	dec := WrapDecoder(stringCodec{}, LengthPrefix)
+
+	buf := bytes.NewBuffer([]byte{})
+	for _, s := range []string{"a", "", "bcd"} {
+		r, err := enc.Encode(s)
+		if err != nil {
+			t.Errorf("Fail: %v\n", err)
+			return
+		}
+		io.Copy(buf, r)
+	}
+
+	for _, want := range []string{"a", "", "bcd"} {
+		var s string
+		if err := dec.Decode(buf, &s); err != nil {
+			t.Errorf("Fail: %v\n", err)
+			return
+		}
+		if s != want {
+			t.Errorf("Fail: %s (want %s)\n", s, want)
+			return
+		}
+	}
+
+	var s string
+	if err := dec.Decode(buf, &s); err == nil {
+		t.Errorf("Must Fail\n")
+		return
+	}
+}
+
+func TestWrapCodec(t *testing.T){
+	c := WrapCodec(Codec{
+		MediaType: "text/plain",
+		Encoder: stringCodec{},
+		Decoder: stringCodec{},
+	}, Zstd)
+
+	if d, ok := c.Decoder.(WrappedDecoder); !ok {
+		t.Errorf("Fail: %T\n", c.Decoder)
+		return
+	} else if _, ok := d.Unwrap().(stringCodec); !ok {
+		t.Errorf("Fail: %T\n", d.Unwrap())
+		return
+	}
+
+	r, err := c.Encoder.Encode("xyz")
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	var s string
+	if err := c.Decoder.Decode(r, &s); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if s != "xyz" {
+		t.Errorf("Fail: %s\n", s)
+		return
+	}
+}
+
+func TestWrapEncodeTo(t *testing.T){
+	enc := WrapEncoder(stringCodec{}, Gzip, Base64)
+	dec := WrapDecoder(stringCodec{}, Gzip, Base64)
+
+	buf := bytes.NewBuffer([]byte{})
+	if err := enc.EncodeTo(buf, "abcde"); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	var s string
+	if err := dec.Decode(buf, &s); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if s != "abcde" {
+		t.Errorf("Fail: %s\n", s)
+		return
+	}
+}
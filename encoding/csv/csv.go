@@ -0,0 +1,357 @@
+// Package encoding/csv implements Encoder/Decoder for CSV
+//
+// Supported data are [][]string and a slice of struct (or pointer to struct).
+// For a slice of struct, the first record is a header,
+// whose names are taken from `csv:"name"` tags or field names.
+// Fields with `csv:"-"` tag are ignored.
+// Fields promoted from nil embedded pointer are encoded as empty,
+// and the embedded struct is allocated on decode.
+// Nil pointer fields are encoded as empty,
+// and empty columns are decoded to nil pointer fields.
+package csv
+
+import (
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+
+	ymdenc "github.com/ymd-h/go/encoding"
+)
+
+type (
+	Encoder struct {}
+	Decoder struct {}
+
+	// encoder adapts csv.Writer to encoding.IEncoder.
+	encoder struct {
+		w *csv.Writer
+	}
+
+	// decoder adapts csv.Reader to encoding.IDecoder.
+	decoder struct {
+		r *csv.Reader
+	}
+
+	// column is a struct field mapped to a CSV column.
+	column struct {
+		name string
+		index []int
+	}
+)
+
+const (
+	// MediaType is registered to encoding.DefaultRegistry on import.
+	MediaType = "text/csv"
+)
+
+var (
+	ErrUnsupportedType = errors.New("Unsupported type")
+)
+
+
+func init(){
+	ymdenc.Register(ymdenc.Codec{
+		MediaType: MediaType,
+		Extensions: []string{".csv"},
+		Encoder: Encoder{},
+		Decoder: Decoder{},
+	})
+}
+
+func newEncoder(w io.Writer) encoder {
+	return encoder{ w: csv.NewWriter(w) }
+}
+
+func newDecoder(r io.Reader) decoder {
+	return decoder{ r: csv.NewReader(r) }
+}
+
+// Encode encodes data and returns encoded io.Reader.
+func (_ Encoder) Encode(data any) (io.Reader, error) {
+	return ymdenc.Encode(newEncoder, data)
+}
+
+// EncodeTo encodes data and writes it to w without intermediate buffer.
+func (_ Encoder) EncodeTo(w io.Writer, data any) error {
+	return ymdenc.EncodeTo(newEncoder, w, data)
+}
+
+// Decode decodes buf io.Reader to ptr.
+func (_ Decoder) Decode(buf io.Reader, ptr any) error {
+	return ymdenc.Decode(newDecoder, buf, ptr)
+}
+
+// columns returns columns of struct type t.
+func columns(t reflect.Type) []column {
+	cols := make([]column, 0, t.NumField())
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("csv"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+
+		cols = append(cols, column{ name: name, index: f.Index })
+	}
+	return cols
+}
+
+// elemStruct returns struct type of slice element t, which might be pointer.
+func elemStruct(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t, (t.Kind() == reflect.Struct)
+}
+
+func (e encoder) Encode(data any) error {
+	if records, ok := data.([][]string); ok {
+		return e.w.WriteAll(records)
+	}
+
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if (v.Kind() != reflect.Slice) && (v.Kind() != reflect.Array) {
+		return fmt.Errorf("%w: %T", ErrUnsupportedType, data)
+	}
+
+	st, ok := elemStruct(v.Type().Elem())
+	if !ok {
+		return fmt.Errorf("%w: %T", ErrUnsupportedType, data)
+	}
+	cols := columns(st)
+
+	header := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = c.name
+	}
+	if err := e.w.Write(header); err != nil {
+		return err
+	}
+
+	for i := 0; i < v.Len(); i++ {
+		row := v.Index(i)
+		if row.Kind() == reflect.Pointer {
+			if row.IsNil() {
+				return fmt.Errorf("Nil element at %d", i)
+			}
+			row = row.Elem()
+		}
+
+		record := make([]string, len(cols))
+		for j, c := range cols {
+			f, err := row.FieldByIndexErr(c.index)
+			if err != nil {
+				// Promoted through nil embedded pointer
+				continue
+			}
+
+			s, err := format(f)
+			if err != nil {
+				return fmt.Errorf("Fail to Format %s at %d: %w", c.name, i, err)
+			}
+			record[j] = s
+		}
+
+		if err := e.w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (d decoder) Decode(ptr any) error {
+	if records, ok := ptr.(*[][]string); ok {
+		r, err := d.r.ReadAll()
+		if err != nil {
+			return err
+		}
+		*records = r
+		return nil
+	}
+
+	v := reflect.ValueOf(ptr)
+	if (v.Kind() != reflect.Pointer) || (v.Elem().Kind() != reflect.Slice) {
+		return fmt.Errorf("%w: %T", ErrUnsupportedType, ptr)
+	}
+	slice := v.Elem()
+	et := slice.Type().Elem()
+
+	st, ok := elemStruct(et)
+	if !ok {
+		return fmt.Errorf("%w: %T", ErrUnsupportedType, ptr)
+	}
+
+	header, err := d.r.Read()
+	if err != nil {
+		return err
+	}
+
+	byName := map[string]column{}
+	for _, c := range columns(st) {
+		byName[c.name] = c
+	}
+
+	// Unknown columns are ignored.
+	cols := make([]*column, len(header))
+	for i, h := range header {
+		if c, ok := byName[h]; ok {
+			cols[i] = &c
+		}
+	}
+
+	result := reflect.MakeSlice(slice.Type(), 0, 0)
+	for line := 2; ; line++ {
+		record, err := d.r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		row := reflect.New(st).Elem()
+		for i, s := range record {
+			if (i >= len(cols)) || (cols[i] == nil) {
+				continue
+			}
+
+			f, ok := fieldByIndexAlloc(row, cols[i].index)
+			if !ok {
+				continue
+			}
+
+			if err := parse(f, s); err != nil {
+				return fmt.Errorf("Fail to Parse %s at line %d: %w", cols[i].name, line, err)
+			}
+		}
+
+		if et.Kind() == reflect.Pointer {
+			row = row.Addr()
+		}
+		result = reflect.Append(result, row)
+	}
+
+	slice.Set(result)
+	return nil
+}
+
+// fieldByIndexAlloc returns nested field like FieldByIndex,
+// but allocates nil embedded pointers on the way.
+// If the pointer cannot be allocated (e.g. unexported embed),
+// false is returned.
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if (i > 0) && (v.Kind() == reflect.Pointer) {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// format converts field value to string.
+// Nil pointer is converted to empty string.
+func format(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", nil
+		}
+		if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+			b, err := m.MarshalText()
+			return string(b), err
+		}
+		v = v.Elem()
+	}
+
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		return string(b), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedType, v.Type())
+	}
+}
+
+// parse sets s to field value.
+// Pointer is allocated unless s is empty.
+func parse(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Pointer {
+		if s == "" {
+			v.SetZero()
+			return nil
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package csv
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ymd-h/go/encoding"
+)
+
+func TestCSV(t *testing.T){
+	type (
+		A struct {
+			A1 string `csv:"a1"`
+			A2 int `csv:"a2"`
+			A3 bool
+			A4 float64 `csv:"-"`
+		}
+	)
+
+	a := []A{
+		{ A1: "12345abcde", A2: 255, A3: true, A4: 1.5 },
+		{ A1: "with,comma", A2: -1, A3: false, A4: 2.5 },
+	}
+
+	enc := Encoder{}
+	dec := Decoder{}
+
+	b, err := enc.Encode(a)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	s, err := io.ReadAll(b)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if want := "a1,a2,A3\n12345abcde,255,true\n\"with,comma\",-1,false\n"; string(s) != want {
+		t.Errorf("Fail: %q (want %q)\n", string(s), want)
+		return
+	}
+
+	var aa []*A
+	err = dec.Decode(strings.NewReader(string(s)), &aa)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	if len(aa) != len(a) {
+		t.Errorf("Fail: %d\n", len(aa))
+		return
+	}
+	for i := range a {
+		if (a[i].A1 != aa[i].A1) ||
+			(a[i].A2 != aa[i].A2) ||
+			(a[i].A3 != aa[i].A3) ||
+			(aa[i].A4 != 0) {
+			t.Errorf("Fail: %+v\n", *aa[i])
+			return
+		}
+	}
+}
+
+type Base struct {
+	ID int `csv:"id"`
+}
+
+func TestCSVNilEmbed(t *testing.T){
+	type (
+		A struct {
+			*Base
+			Name string `csv:"name"`
+		}
+	)
+
+	a := []A{
+		{ Name: "nil" },
+		{ Base: &Base{ ID: 1 }, Name: "one" },
+	}
+
+	b, err := Encoder{}.Encode(a)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	s, err := io.ReadAll(b)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if want := "id,name\n,nil\n1,one\n"; string(s) != want {
+		t.Errorf("Fail: %q (want %q)\n", string(s), want)
+		return
+	}
+
+	var aa []A
+	err = Decoder{}.Decode(strings.NewReader("name,id\nx,2\n"), &aa)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if (len(aa) != 1) || (aa[0].Base == nil) || (aa[0].ID != 2) || (aa[0].Name != "x") {
+		t.Errorf("Fail: %+v\n", aa)
+		return
+	}
+}
+
+func TestCSVPointer(t *testing.T){
+	type (
+		A struct {
+			ID int `csv:"id"`
+			N *int `csv:"n"`
+			T *time.Time `csv:"t"`
+		}
+	)
+
+	n := 3
+	tm := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	a := []A{
+		{ ID: 1 },
+		{ ID: 2, N: &n, T: &tm },
+	}
+
+	b, err := Encoder{}.Encode(a)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	s, err := io.ReadAll(b)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if want := "id,n,t\n1,,\n2,3,2024-01-02T03:04:05Z\n"; string(s) != want {
+		t.Errorf("Fail: %q (want %q)\n", string(s), want)
+		return
+	}
+
+	var aa []A
+	if err := (Decoder{}).Decode(strings.NewReader(string(s)), &aa); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if (len(aa) != 2) || (aa[0].N != nil) || (aa[0].T != nil) {
+		t.Errorf("Fail: %+v\n", aa)
+		return
+	}
+	if (aa[1].N == nil) || (*aa[1].N != n) || (aa[1].T == nil) || !aa[1].T.Equal(tm) {
+		t.Errorf("Fail: %+v\n", aa[1])
+		return
+	}
+}
+
+func TestCSVRecords(t *testing.T){
+	records := [][]string{{"a", "b"}, {"1", "2"}}
+
+	b, err := Encoder{}.Encode(records)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	var rr [][]string
+	if err := (Decoder{}).Decode(b, &rr); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	if (len(rr) != 2) || (rr[0][0] != "a") || (rr[1][1] != "2") {
+		t.Errorf("Fail: %v\n", rr)
+		return
+	}
+}
+
+func TestCSVError(t *testing.T){
+	if _, err := (Encoder{}).Encode(1); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Must be ErrUnsupportedType: %v\n", err)
+		return
+	}
+
+	var a []struct {
+		A int `csv:"a"`
+	}
+	if err := (Decoder{}).Decode(strings.NewReader("a\nx\n"), &a); err == nil {
+		t.Errorf("Must Fail\n")
+		return
+	}
+}
+
+func TestRegister(t *testing.T){
+	c, err := encoding.Lookup("text/csv; charset=utf-8")
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if c.MediaType != MediaType {
+		t.Errorf("Fail: %s\n", c.MediaType)
+		return
+	}
+
+	c, err = encoding.LookupExtension(".csv")
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if _, ok := c.Decoder.(Decoder); !ok {
+		t.Errorf("Fail: %T\n", c.Decoder)
+		return
+	}
+}
@@ -3,6 +3,7 @@ package encoding
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 )
@@ -15,11 +16,22 @@ type (
 	IDecoder interface {
 		Decode(any) error
 	}
+
+	limitedReader struct {
+		r io.Reader
+		n int64
+	}
+)
+
+var (
+	ErrInputTooLarge = errors.New("Input too large")
 )
 
-// Encode encodes data with IEncoder and returns encoded io.Reader.
+// Encode encodes data with IEncoder and returns encoded io.Reader,
+// which is *bytes.Buffer.
 // If data is nil, nil io.Reader is returned.
 // The encoding/json.NewEncoder in standard library can be passed.
+// Use EncodePooled to reuse buffers.
 func Encode[E IEncoder](newEncoder func(io.Writer) E, data any) (io.Reader, error) {
 	if data == nil {
 		return nil, nil
@@ -35,7 +47,8 @@ func Encode[E IEncoder](newEncoder func(io.Writer) E, data any) (io.Reader, erro
 	return buf, nil
 }
 
-// Decode decodes buf io.Reader with IDecoder to ptr.
+// Decode decodes buf io.Reader with IDecoder to ptr,
+// then validates it with Validate.
 // The encoding/json.NewDecoder in standard library can be passed.
 func Decode[D IDecoder](newDecoder func(io.Reader) D, buf io.Reader, ptr any) error {
 	if ptr == nil {
@@ -47,5 +60,34 @@ func Decode[D IDecoder](newDecoder func(io.Reader) D, buf io.Reader, ptr any) er
 		return fmt.Errorf("Fail to Decode: %w", err)
 	}
 
+	if err := Validate(ptr); err != nil {
+		return fmt.Errorf("Fail to Validate: %w", err)
+	}
+
 	return nil
 }
+
+// LimitReader returns io.Reader which reads at most n bytes from r.
+// Unlike io.LimitReader, ErrInputTooLarge is returned
+// when r has more than n bytes.
+func LimitReader(r io.Reader, n int64) io.Reader {
+	return &limitedReader{ r: r, n: n }
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		var b [1]byte
+		n, err := l.r.Read(b[:])
+		if n > 0 {
+			return 0, ErrInputTooLarge
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
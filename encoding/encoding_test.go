@@ -4,7 +4,9 @@ import (
 	"encoding/gob"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"io"
+	"strings"
 	"testing"
 )
 
@@ -81,3 +83,21 @@ func TestEncoding(t *testing.T){
 		})
 	}
 }
+
+func TestLimitReader(t *testing.T){
+	b, err := io.ReadAll(LimitReader(strings.NewReader("abc"), 3))
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if string(b) != "abc" {
+		t.Errorf("Fail: %s\n", string(b))
+		return
+	}
+
+	_, err = io.ReadAll(LimitReader(strings.NewReader("abcd"), 3))
+	if !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("Must be ErrInputTooLarge: %v\n", err)
+		return
+	}
+}
@@ -1,3 +1,16 @@
 module github.com/ymd-h/go/encoding
 
 go 1.20
+
+require (
+	github.com/BurntSushi/toml v1.4.0
+	github.com/fxamacker/cbor/v2 v2.7.0
+	github.com/klauspost/compress v1.16.7
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+)
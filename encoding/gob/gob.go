@@ -13,12 +13,41 @@ type (
 	Decoder struct {}
 )
 
+const (
+	// MediaType is registered to encoding.DefaultRegistry on import.
+	MediaType = "application/x-gob"
+)
+
+func init(){
+	encoding.Register(encoding.Codec{
+		MediaType: MediaType,
+		Extensions: []string{".gob"},
+		Encoder: Encoder{},
+		Decoder: Decoder{},
+	})
+}
+
 // Encode encodes data and returns encoded io.Reader.
 func (_ Encoder) Encode(data any) (io.Reader, error) {
 	return encoding.Encode(gob.NewEncoder, data)
 }
 
+// EncodeTo encodes data and writes it to w without intermediate buffer.
+func (_ Encoder) EncodeTo(w io.Writer, data any) error {
+	return encoding.EncodeTo(gob.NewEncoder, w, data)
+}
+
 // Decode decodes buf io.Reader to ptr.
 func (_ Decoder) Decode(buf io.Reader, ptr any) error {
 	return encoding.Decode(gob.NewDecoder, buf, ptr)
 }
+
+// EncodeStream creates a new StreamEncoder writing Gob stream to w.
+func EncodeStream(w io.Writer) *encoding.StreamEncoder {
+	return encoding.EncodeStream(gob.NewEncoder, w)
+}
+
+// DecodeStream[T] creates a new StreamDecoder[T] reading Gob stream from r.
+func DecodeStream[T any](r io.Reader) *encoding.StreamDecoder[T] {
+	return encoding.DecodeStream[T](gob.NewDecoder, r)
+}
@@ -3,6 +3,7 @@ package gob
 import (
 	"testing"
 
+	"github.com/ymd-h/go/encoding"
 	"github.com/ymd-h/go/slices"
 )
 
@@ -55,3 +56,25 @@ func TestJSON(t *testing.T){
 	}
 
 }
+
+func TestRegister(t *testing.T){
+	c, err := encoding.Lookup("application/x-gob")
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if c.MediaType != MediaType {
+		t.Errorf("Fail: %s\n", c.MediaType)
+		return
+	}
+
+	c, err = encoding.LookupExtension(".gob")
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if _, ok := c.Decoder.(Decoder); !ok {
+		t.Errorf("Fail: %T\n", c.Decoder)
+		return
+	}
+}
@@ -0,0 +1,175 @@
+package json
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+)
+
+type (
+	// canonicalEncoder writes canonical JSON defined by RFC 8785.
+	canonicalEncoder struct {
+		w io.Writer
+	}
+)
+
+
+func (e canonicalEncoder) Encode(data any) error {
+	// Marshal once to respect json tags and json.Marshaler
+	buf := bytes.NewBuffer([]byte{})
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(data); err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(buf)
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(e.w)
+	if err := writeCanonical(w, v); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// writeCanonical writes v decoded with UseNumber.
+func writeCanonical(w *bufio.Writer, v any) error {
+	switch v := v.(type) {
+	case nil:
+		w.WriteString("null")
+	case bool:
+		w.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return fmt.Errorf("Fail to Canonicalize Number %s: %w", v, err)
+		}
+		s, err := formatNumber(f)
+		if err != nil {
+			return err
+		}
+		w.WriteString(s)
+	case string:
+		writeString(w, v)
+	case []any:
+		w.WriteByte('[')
+		for i, e := range v {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			if err := writeCanonical(w, e); err != nil {
+				return err
+			}
+		}
+		w.WriteByte(']')
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		// Keys are sorted by UTF-16 code units.
+		sort.Slice(keys, func(i, j int) bool {
+			return lessUTF16(keys[i], keys[j])
+		})
+
+		w.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			writeString(w, k)
+			w.WriteByte(':')
+			if err := writeCanonical(w, v[k]); err != nil {
+				return err
+			}
+		}
+		w.WriteByte('}')
+	default:
+		return fmt.Errorf("Fail to Canonicalize: unexpected %T", v)
+	}
+	return nil
+}
+
+// formatNumber formats f like ECMAScript Number.prototype.toString.
+func formatNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("Fail to Canonicalize Number: %v", f)
+	}
+	if f == 0 {
+		// Including -0
+		return "0", nil
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); (abs < 1e-6) || (abs >= 1e21) {
+		format = 'e'
+	}
+
+	s := strconv.FormatFloat(f, format, -1, 64)
+	if format == 'e' {
+		// e-07 -> e-7
+		n := len(s)
+		if (n >= 4) && (s[n-4] == 'e') && (s[n-2] == '0') {
+			s = s[:n-2] + s[n-1:]
+		}
+	}
+	return s, nil
+}
+
+// writeString writes s with minimal escape like ECMAScript JSON.stringify.
+func writeString(w *bufio.Writer, s string) {
+	const hex = "0123456789abcdef"
+
+	w.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			w.WriteString(`\"`)
+		case '\\':
+			w.WriteString(`\\`)
+		case '\b':
+			w.WriteString(`\b`)
+		case '\f':
+			w.WriteString(`\f`)
+		case '\n':
+			w.WriteString(`\n`)
+		case '\r':
+			w.WriteString(`\r`)
+		case '\t':
+			w.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				w.WriteString(`\u00`)
+				w.WriteByte(hex[r >> 4])
+				w.WriteByte(hex[r & 0xF])
+			} else {
+				w.WriteRune(r)
+			}
+		}
+	}
+	w.WriteByte('"')
+}
+
+// lessUTF16 compares a and b as UTF-16 code units.
+func lessUTF16(a, b string) bool {
+	ua := utf16.Encode([]rune(a))
+	ub := utf16.Encode([]rune(b))
+	for i := 0; (i < len(ua)) && (i < len(ub)); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
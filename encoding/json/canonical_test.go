@@ -0,0 +1,63 @@
+package json
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCanonical(t *testing.T){
+	// Examples from RFC 8785
+	tests := []struct {
+		input string
+		want string
+	}{
+		{
+			input: `{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "€$\u000F\u000aA'B\"\\\\\"\/",
+  "literals": [null, true, false]
+}`,
+			want: `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			input: `{
+  "€": "Euro Sign",
+  "\r": "Carriage Return",
+  "דּ": "Hebrew Letter Dalet With Dagesh",
+  "1": "One",
+  "😀": "Emoji: Grinning Face",
+  "\u0080": "Control",
+  "ö": "Latin Small Letter O With Diaeresis"
+}`,
+			want: `{"\r":"Carriage Return","1":"One","` + "\u0080" + `":"Control","ö":"Latin Small Letter O With Diaeresis","€":"Euro Sign","😀":"Emoji: Grinning Face","דּ":"Hebrew Letter Dalet With Dagesh"}`,
+		},
+		{ input: `[-0, 1e21, 1e-7, 123456789012, 0.1]`, want: `[0,1e+21,1e-7,123456789012,0.1]` },
+	}
+
+	enc := NewEncoder(Canonical())
+	for _, tt := range tests {
+		var v any
+		if err := (Decoder{}).Decode(strings.NewReader(tt.input), &v); err != nil {
+			t.Errorf("Fail: %v\n", err)
+			return
+		}
+
+		r, err := enc.Encode(v)
+		if err != nil {
+			t.Errorf("Fail: %v\n", err)
+			return
+		}
+
+		b, err := io.ReadAll(r)
+		if err != nil {
+			t.Errorf("Fail: %v\n", err)
+			return
+		}
+
+		if string(b) != tt.want {
+			t.Errorf("Fail:\n%s\n(want)\n%s\n", string(b), tt.want)
+			return
+		}
+	}
+}
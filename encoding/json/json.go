@@ -3,22 +3,264 @@ package json
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 
 	"github.com/ymd-h/go/encoding"
 )
 
 type (
-	Encoder struct {}
-	Decoder struct {}
+	// Encoder encodes JSON.
+	// The zero value encodes with the defaults of encoding/json.
+	// Use NewEncoder to configure it.
+	Encoder struct {
+		prefix string
+		indent string
+		disableHTMLEscape bool
+		canonical bool
+	}
+
+	// EncoderOption configures Encoder.
+	EncoderOption func(*Encoder)
+
+	// Decoder decodes JSON.
+	// The zero value decodes with the defaults of encoding/json.
+	// Use NewDecoder to configure it.
+	Decoder struct {
+		disallowUnknownFields bool
+		useNumber bool
+		disallowTrailingData bool
+		maxInputSize int64
+		maxDepth int
+	}
+
+	// DecoderOption configures Decoder.
+	DecoderOption func(*Decoder)
+
+	// decoder adapts json.Decoder to encoding.IDecoder with checks.
+	decoder struct {
+		dec *json.Decoder
+		disallowTrailingData bool
+	}
+
+	// depthReader fails when nesting depth of JSON exceeds max.
+	depthReader struct {
+		r io.Reader
+		max int
+		depth int
+		inString bool
+		escape bool
+	}
+)
+
+const (
+	// MediaType is registered to encoding.DefaultRegistry on import.
+	MediaType = "application/json"
 )
 
+var (
+	ErrTrailingData = errors.New("Trailing data after JSON value")
+	ErrTooDeep = errors.New("Nesting too deep")
+)
+
+func init(){
+	encoding.Register(encoding.Codec{
+		MediaType: MediaType,
+		Extensions: []string{".json"},
+		Encoder: Encoder{},
+		Decoder: Decoder{},
+	})
+}
+
+// NewEncoder creates a new Encoder with options.
+func NewEncoder(options ...EncoderOption) Encoder {
+	var e Encoder
+	for _, o := range options {
+		o(&e)
+	}
+	return e
+}
+
+// Indent makes Encoder write indented JSON like json.MarshalIndent.
+func Indent(prefix, indent string) EncoderOption {
+	return func(e *Encoder){
+		e.prefix = prefix
+		e.indent = indent
+	}
+}
+
+// EscapeHTML sets whether Encoder escapes &, < and > in strings.
+// The default is true.
+func EscapeHTML(on bool) EncoderOption {
+	return func(e *Encoder){
+		e.disableHTMLEscape = !on
+	}
+}
+
+// Canonical makes Encoder write canonical JSON defined by RFC 8785 (JCS),
+// where object keys are sorted and numbers are normalized.
+// Canonical JSON has neither indent, HTML escape, nor trailing newline,
+// so that Indent and EscapeHTML are ignored.
+func Canonical() EncoderOption {
+	return func(e *Encoder){
+		e.canonical = true
+	}
+}
+
+func (e Encoder) newEncoder(w io.Writer) encoding.IEncoder {
+	if e.canonical {
+		return canonicalEncoder{ w: w }
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent(e.prefix, e.indent)
+	enc.SetEscapeHTML(!e.disableHTMLEscape)
+	return enc
+}
+
 // Encode encodes data and returns encoded io.Reader.
-func (_ Encoder) Encode(data any) (io.Reader, error) {
-	return encoding.Encode(json.NewEncoder, data)
+func (e Encoder) Encode(data any) (io.Reader, error) {
+	return encoding.Encode(e.newEncoder, data)
+}
+
+// EncodeTo encodes data and writes it to w without intermediate buffer.
+func (e Encoder) EncodeTo(w io.Writer, data any) error {
+	return encoding.EncodeTo(e.newEncoder, w, data)
+}
+
+// NewDecoder creates a new Decoder with options.
+func NewDecoder(options ...DecoderOption) Decoder {
+	var d Decoder
+	for _, o := range options {
+		o(&d)
+	}
+	return d
+}
+
+// DisallowUnknownFields makes Decoder fail when JSON object has
+// a key which doesn't match any struct field.
+func DisallowUnknownFields() DecoderOption {
+	return func(d *Decoder){
+		d.disallowUnknownFields = true
+	}
+}
+
+// UseNumber makes Decoder decode numbers into any as json.Number
+// instead of float64.
+func UseNumber() DecoderOption {
+	return func(d *Decoder){
+		d.useNumber = true
+	}
+}
+
+// DisallowTrailingData makes Decoder fail with ErrTrailingData
+// when data remains after the JSON value.
+func DisallowTrailingData() DecoderOption {
+	return func(d *Decoder){
+		d.disallowTrailingData = true
+	}
+}
+
+// MaxInputSize makes Decoder fail with encoding.ErrInputTooLarge
+// when input is larger than n bytes.
+func MaxInputSize(n int64) DecoderOption {
+	return func(d *Decoder){
+		d.maxInputSize = n
+	}
+}
+
+// MaxDepth makes Decoder fail with ErrTooDeep
+// when arrays and objects are nested deeper than n.
+func MaxDepth(n int) DecoderOption {
+	return func(d *Decoder){
+		d.maxDepth = n
+	}
+}
+
+func (d Decoder) newDecoder(r io.Reader) decoder {
+	if d.maxInputSize > 0 {
+		r = encoding.LimitReader(r, d.maxInputSize)
+	}
+	if d.maxDepth > 0 {
+		r = &depthReader{ r: r, max: d.maxDepth }
+	}
+
+	dec := json.NewDecoder(r)
+	if d.disallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if d.useNumber {
+		dec.UseNumber()
+	}
+
+	return decoder{ dec: dec, disallowTrailingData: d.disallowTrailingData }
 }
 
 // Decode decodes buf io.Reader to ptr.
-func (_ Decoder) Decode(buf io.Reader, ptr any) error {
-	return encoding.Decode(json.NewDecoder, buf, ptr)
+func (d Decoder) Decode(buf io.Reader, ptr any) error {
+	return encoding.Decode(d.newDecoder, buf, ptr)
+}
+
+func (d decoder) Decode(ptr any) error {
+	if err := d.dec.Decode(ptr); err != nil {
+		return err
+	}
+
+	if d.disallowTrailingData {
+		if _, err := d.dec.Token(); err != io.EOF {
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrTrailingData, err)
+			}
+			return ErrTrailingData
+		}
+	}
+
+	return nil
+}
+
+func (r *depthReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	for _, b := range p[:n] {
+		switch {
+		case r.escape:
+			r.escape = false
+		case r.inString:
+			switch b {
+			case '\\':
+				r.escape = true
+			case '"':
+				r.inString = false
+			}
+		case b == '"':
+			r.inString = true
+		case (b == '{') || (b == '['):
+			r.depth += 1
+			if r.depth > r.max {
+				return 0, fmt.Errorf("%w: > %d", ErrTooDeep, r.max)
+			}
+		case (b == '}') || (b == ']'):
+			r.depth -= 1
+		}
+	}
+	return n, err
+}
+
+// EncodeStream creates a new StreamEncoder writing JSON Lines to w.
+func EncodeStream(w io.Writer) *encoding.StreamEncoder {
+	return encoding.EncodeStream(json.NewEncoder, w)
+}
+
+// DecodeStream[T] creates a new StreamDecoder[T] reading JSON Lines from r.
+func DecodeStream[T any](r io.Reader) *encoding.StreamDecoder[T] {
+	return DecodeStreamWith[T](Decoder{}, r)
+}
+
+// DecodeStreamWith[T] creates a new StreamDecoder[T] reading JSON Lines
+// from r with options of d.
+// MaxInputSize limits the whole stream, and
+// DisallowTrailingData is ignored since a stream has multiple values.
+func DecodeStreamWith[T any](d Decoder, r io.Reader) *encoding.StreamDecoder[T] {
+	d.disallowTrailingData = false
+	return encoding.DecodeStream[T](d.newDecoder, r)
 }
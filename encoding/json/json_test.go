@@ -1,8 +1,14 @@
 package json
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
 	"testing"
 
+	"github.com/ymd-h/go/encoding"
 	"github.com/ymd-h/go/slices"
 )
 
@@ -55,3 +61,247 @@ func TestJSON(t *testing.T){
 	}
 
 }
+
+func TestJSONStream(t *testing.T){
+	buf := bytes.NewBuffer([]byte{})
+
+	enc := EncodeStream(buf)
+	for i := 0; i < 3; i++ {
+		if err := enc.Encode(i); err != nil {
+			t.Errorf("Fail: %v\n", err)
+			return
+		}
+	}
+
+	// JSON Lines
+	if buf.String() != "0\n1\n2\n" {
+		t.Errorf("Fail: %q\n", buf.String())
+		return
+	}
+
+	dec := DecodeStream[int](buf)
+	for i := 0; i < 3; i++ {
+		if !dec.Next() || (dec.Value() != i) {
+			t.Errorf("Fail: %d\n", i)
+			return
+		}
+	}
+	if dec.Next() || (dec.Err() != nil) {
+		t.Errorf("Fail: %v\n", dec.Err())
+		return
+	}
+}
+
+func TestJSONStreamOptions(t *testing.T){
+	type A struct {
+		A1 int `json:"a1"`
+	}
+
+	dec := DecodeStreamWith[A](
+		NewDecoder(DisallowUnknownFields(), DisallowTrailingData()),
+		strings.NewReader("{\"a1\": 1}\n{\"b\": 2}\n"),
+	)
+	if !dec.Next() || (dec.Value().A1 != 1) {
+		t.Errorf("Fail: %v\n", dec.Err())
+		return
+	}
+	if dec.Next() || (dec.Err() == nil) {
+		t.Errorf("Must Fail\n")
+		return
+	}
+
+	dec = DecodeStreamWith[A](
+		NewDecoder(MaxInputSize(20)),
+		strings.NewReader("{\"a1\": 1}\n{\"a1\": 2}\n{\"a1\": 3}\n"),
+	)
+	for dec.Next() {
+	}
+	if !errors.Is(dec.Err(), encoding.ErrInputTooLarge) {
+		t.Errorf("Must be ErrInputTooLarge: %v\n", dec.Err())
+		return
+	}
+}
+
+func TestRegister(t *testing.T){
+	c, err := encoding.Lookup("application/json")
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if c.MediaType != MediaType {
+		t.Errorf("Fail: %s\n", c.MediaType)
+		return
+	}
+
+	c, err = encoding.LookupExtension(".json")
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if _, ok := c.Decoder.(Decoder); !ok {
+		t.Errorf("Fail: %T\n", c.Decoder)
+		return
+	}
+}
+
+func TestValidate(t *testing.T){
+	var a struct {
+		A1 string `json:"a1" validate:"required"`
+		A2 int `json:"a2" validate:"max=10"`
+	}
+
+	dec := Decoder{}
+	if err := dec.Decode(strings.NewReader(`{"a1": "a", "a2": 1}`), &a); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	err := dec.Decode(strings.NewReader(`{"a1": "a", "a2": 11}`), &a)
+	if !errors.Is(err, encoding.ErrValidation) {
+		t.Errorf("Must be ErrValidation: %v\n", err)
+		return
+	}
+}
+
+func TestDecoderOptions(t *testing.T){
+	type A struct {
+		A1 any `json:"a1"`
+	}
+
+	tests := []struct {
+		name string
+		dec Decoder
+		input string
+		fail bool
+		err error
+	}{
+		{ name: "default", dec: Decoder{}, input: `{"a1": 1, "b": 2} {}` },
+		{
+			name: "unknown",
+			dec: NewDecoder(DisallowUnknownFields()),
+			input: `{"a1": 1, "b": 2}`,
+			fail: true,
+		},
+		{
+			name: "trailing",
+			dec: NewDecoder(DisallowTrailingData()),
+			input: `{"a1": 1} {}`,
+			fail: true,
+			err: ErrTrailingData,
+		},
+		{
+			name: "trailing-space",
+			dec: NewDecoder(DisallowTrailingData()),
+			input: "{\"a1\": 1}\n",
+		},
+		{
+			name: "trailing-size",
+			dec: NewDecoder(DisallowTrailingData(), MaxInputSize(15)),
+			input: `{"a1": 1}                    1`,
+			fail: true,
+			err: encoding.ErrInputTooLarge,
+		},
+		{
+			name: "size",
+			dec: NewDecoder(MaxInputSize(10)),
+			input: `{"a1": "0123456789"}`,
+			fail: true,
+			err: encoding.ErrInputTooLarge,
+		},
+		{
+			name: "size-ok",
+			dec: NewDecoder(MaxInputSize(20)),
+			input: `{"a1": "0123456789"}`,
+		},
+		{
+			name: "depth",
+			dec: NewDecoder(MaxDepth(2)),
+			input: `{"a1": [[1]]}`,
+			fail: true,
+			err: ErrTooDeep,
+		},
+		{
+			name: "depth-string",
+			dec: NewDecoder(MaxDepth(2)),
+			input: `{"a1": ["[[\"[{"]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		var a A
+		err := tt.dec.Decode(strings.NewReader(tt.input), &a)
+		if !tt.fail {
+			if err != nil {
+				t.Errorf("Fail %s: %v\n", tt.name, err)
+			}
+			continue
+		}
+
+		if err == nil {
+			t.Errorf("Must Fail %s\n", tt.name)
+			continue
+		}
+		if (tt.err != nil) && !errors.Is(err, tt.err) {
+			t.Errorf("Fail %s: %v\n", tt.name, err)
+		}
+	}
+
+	var a A
+	err := NewDecoder(UseNumber()).Decode(strings.NewReader(`{"a1": 1}`), &a)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if _, ok := a.A1.(json.Number); !ok {
+		t.Errorf("Fail: %T\n", a.A1)
+		return
+	}
+}
+
+func TestEncoderOptions(t *testing.T){
+	type A struct {
+		A1 string `json:"a1"`
+		A2 []int `json:"a2"`
+	}
+	a := A{ A1: "<&>", A2: []int{1} }
+
+	tests := []struct {
+		name string
+		enc Encoder
+		want string
+	}{
+		{
+			name: "default",
+			enc: Encoder{},
+			want: `{"a1":"\u003c\u0026\u003e","a2":[1]}` + "\n",
+		},
+		{
+			name: "indent",
+			enc: NewEncoder(Indent("", "  "), EscapeHTML(false)),
+			want: "{\n  \"a1\": \"<&>\",\n  \"a2\": [\n    1\n  ]\n}\n",
+		},
+		{
+			name: "canonical",
+			enc: NewEncoder(Canonical(), Indent("", "  ")),
+			want: `{"a1":"<&>","a2":[1]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		r, err := tt.enc.Encode(a)
+		if err != nil {
+			t.Errorf("Fail %s: %v\n", tt.name, err)
+			continue
+		}
+
+		b, err := io.ReadAll(r)
+		if err != nil {
+			t.Errorf("Fail %s: %v\n", tt.name, err)
+			continue
+		}
+
+		if string(b) != tt.want {
+			t.Errorf("Fail %s: %q (want %q)\n", tt.name, string(b), tt.want)
+		}
+	}
+}
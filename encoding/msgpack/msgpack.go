@@ -0,0 +1,54 @@
+// Package encoding/msgpack implements Encoder/Decoder for MessagePack
+package msgpack
+
+import (
+	"io"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/ymd-h/go/encoding"
+)
+
+type (
+	Encoder struct {}
+	Decoder struct {}
+)
+
+const (
+	// MediaType is registered to encoding.DefaultRegistry on import.
+	MediaType = "application/msgpack"
+)
+
+func init(){
+	encoding.Register(encoding.Codec{
+		MediaType: MediaType,
+		Aliases: []string{"application/x-msgpack", "application/vnd.msgpack"},
+		Extensions: []string{".msgpack", ".mpk"},
+		Encoder: Encoder{},
+		Decoder: Decoder{},
+	})
+}
+
+// Encode encodes data and returns encoded io.Reader.
+func (_ Encoder) Encode(data any) (io.Reader, error) {
+	return encoding.Encode(msgpack.NewEncoder, data)
+}
+
+// EncodeTo encodes data and writes it to w without intermediate buffer.
+func (_ Encoder) EncodeTo(w io.Writer, data any) error {
+	return encoding.EncodeTo(msgpack.NewEncoder, w, data)
+}
+
+// Decode decodes buf io.Reader to ptr.
+func (_ Decoder) Decode(buf io.Reader, ptr any) error {
+	return encoding.Decode(msgpack.NewDecoder, buf, ptr)
+}
+
+// EncodeStream creates a new StreamEncoder writing MessagePack stream to w.
+func EncodeStream(w io.Writer) *encoding.StreamEncoder {
+	return encoding.EncodeStream(msgpack.NewEncoder, w)
+}
+
+// DecodeStream[T] creates a new StreamDecoder[T] reading MessagePack stream from r.
+func DecodeStream[T any](r io.Reader) *encoding.StreamDecoder[T] {
+	return encoding.DecodeStream[T](msgpack.NewDecoder, r)
+}
@@ -0,0 +1,137 @@
+package encoding
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+type (
+	// ICodecEncoderTo is interface for Encoder in subpackages,
+	// which can write to io.Writer directly.
+	ICodecEncoderTo interface {
+		EncodeTo(io.Writer, any) error
+	}
+
+	// PooledReader is io.Reader backed by a pooled buffer.
+	// The buffer is returned to the pool when it is read until io.EOF,
+	// written out by WriteTo, or closed.
+	// After that, PooledReader behaves as an empty reader.
+	// Nil *PooledReader is an empty reader, too.
+	PooledReader struct {
+		buf *bytes.Buffer
+	}
+)
+
+const (
+	// maxPooledBufferSize is the maximum capacity kept in the pool,
+	// so that a rare huge data doesn't stay in memory.
+	maxPooledBufferSize = 64 * 1024
+)
+
+var (
+	bufferPool = sync.Pool{
+		New: func() any {
+			return bytes.NewBuffer(make([]byte, 0, 512))
+		},
+	}
+)
+
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// release returns the buffer to the pool.
+func (r *PooledReader) release() {
+	if (r != nil) && (r.buf != nil) {
+		putBuffer(r.buf)
+		r.buf = nil
+	}
+}
+
+// Read reads data. At io.EOF, the buffer is returned to the pool.
+func (r *PooledReader) Read(p []byte) (int, error) {
+	if (r == nil) || (r.buf == nil) {
+		return 0, io.EOF
+	}
+
+	n, err := r.buf.Read(p)
+	if (err == io.EOF) || (r.buf.Len() == 0) {
+		r.release()
+	}
+	return n, err
+}
+
+// WriteTo writes remaining data to w without extra copy,
+// then the buffer is returned to the pool.
+func (r *PooledReader) WriteTo(w io.Writer) (int64, error) {
+	if (r == nil) || (r.buf == nil) {
+		return 0, nil
+	}
+
+	n, err := r.buf.WriteTo(w)
+	if err == nil {
+		r.release()
+	}
+	return n, err
+}
+
+// Len returns the number of unread bytes.
+func (r *PooledReader) Len() int {
+	if (r == nil) || (r.buf == nil) {
+		return 0
+	}
+	return r.buf.Len()
+}
+
+// Close returns the buffer to the pool without reading remaining data.
+func (r *PooledReader) Close() error {
+	r.release()
+	return nil
+}
+
+// EncodePooled encodes data with IEncoder and returns *PooledReader
+// backed by a pooled buffer.
+// If data is nil, nil *PooledReader is returned,
+// which behaves as an empty reader.
+// PooledReader should be read until io.EOF or closed,
+// so that the buffer is reused.
+func EncodePooled[E IEncoder](newEncoder func(io.Writer) E, data any) (*PooledReader, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	buf := getBuffer()
+
+	err := newEncoder(buf).Encode(data)
+	if err != nil {
+		putBuffer(buf)
+		return nil, fmt.Errorf("Fail to Encode: %w", err)
+	}
+
+	return &PooledReader{ buf: buf }, nil
+}
+
+// EncodeTo encodes data with IEncoder and writes it to w directly.
+// If data is nil, nothing is written.
+func EncodeTo[E IEncoder](newEncoder func(io.Writer) E, w io.Writer, data any) error {
+	if data == nil {
+		return nil
+	}
+
+	if err := newEncoder(w).Encode(data); err != nil {
+		return fmt.Errorf("Fail to Encode: %w", err)
+	}
+
+	return nil
+}
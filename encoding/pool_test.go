@@ -0,0 +1,170 @@
+package encoding
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+type (
+	benchData struct {
+		ID int `json:"id"`
+		Name string `json:"name"`
+		Tags []string `json:"tags"`
+		Score float64 `json:"score"`
+	}
+)
+
+func TestPooledReader(t *testing.T){
+	p, err := EncodePooled(json.NewEncoder, map[string]int{"a": 1})
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	if p.Len() != len("{\"a\":1}\n") {
+		t.Errorf("Fail: %d\n", p.Len())
+		return
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, p); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if buf.String() != "{\"a\":1}\n" {
+		t.Errorf("Fail: %s\n", buf.String())
+		return
+	}
+
+	// Released buffer must not be read again.
+	if p.buf != nil {
+		t.Errorf("Fail: buffer is not released\n")
+		return
+	}
+	if n, err := p.Read(make([]byte, 1)); (n != 0) || (err != io.EOF) {
+		t.Errorf("Fail: %d, %v\n", n, err)
+		return
+	}
+
+	r, err := EncodePooled(json.NewEncoder, 1)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if string(b) != "1\n" {
+		t.Errorf("Fail: %s\n", string(b))
+		return
+	}
+
+	r, _ = EncodePooled(json.NewEncoder, 2)
+	if err := r.Close(); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if r.Len() != 0 {
+		t.Errorf("Fail: %d\n", r.Len())
+		return
+	}
+}
+
+func TestPooledReaderNil(t *testing.T){
+	p, err := EncodePooled(json.NewEncoder, nil)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	// Typed nil stored in interface is not nil.
+	var r io.ReadCloser = p
+	defer r.Close()
+
+	if n, err := r.Read(make([]byte, 1)); (n != 0) || (err != io.EOF) {
+		t.Errorf("Fail: %d, %v\n", n, err)
+		return
+	}
+	if n, err := p.WriteTo(io.Discard); (n != 0) || (err != nil) {
+		t.Errorf("Fail: %d, %v\n", n, err)
+		return
+	}
+	if p.Len() != 0 {
+		t.Errorf("Fail: %d\n", p.Len())
+		return
+	}
+}
+
+func TestEncodeBuffer(t *testing.T){
+	r, err := Encode(json.NewEncoder, 1)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	// Callers might depend on the concrete type.
+	if _, ok := r.(*bytes.Buffer); !ok {
+		t.Errorf("Fail: %T\n", r)
+		return
+	}
+}
+
+func TestEncodeTo(t *testing.T){
+	var buf bytes.Buffer
+	if err := EncodeTo(json.NewEncoder, &buf, []int{1, 2}); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if buf.String() != "[1,2]\n" {
+		t.Errorf("Fail: %s\n", buf.String())
+		return
+	}
+
+	if err := EncodeTo(json.NewEncoder, &buf, make(chan int)); err == nil {
+		t.Errorf("Must Fail\n")
+		return
+	}
+}
+
+func newBenchData() []benchData {
+	d := make([]benchData, 32)
+	for i := range d {
+		d[i] = benchData{
+			ID: i,
+			Name: "benchmark",
+			Tags: []string{"a", "b", "c", "d"},
+			Score: 3.14,
+		}
+	}
+	return d
+}
+
+func BenchmarkEncode(b *testing.B){
+	d := newBenchData()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r, _ := Encode(json.NewEncoder, d)
+		io.Copy(io.Discard, r)
+	}
+}
+
+func BenchmarkEncodePooled(b *testing.B){
+	d := newBenchData()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r, _ := EncodePooled(json.NewEncoder, d)
+		io.Copy(io.Discard, r)
+	}
+}
+
+func BenchmarkEncodeTo(b *testing.B){
+	d := newBenchData()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		EncodeTo(json.NewEncoder, io.Discard, d)
+	}
+}
@@ -0,0 +1,305 @@
+package encoding
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type (
+	// ICodecEncoder is interface for Encoder in subpackages.
+	ICodecEncoder interface {
+		Encode(any) (io.Reader, error)
+	}
+
+	// ICodecDecoder is interface for Decoder in subpackages.
+	ICodecDecoder interface {
+		Decode(io.Reader, any) error
+	}
+
+	// Codec is a pair of Encoder and Decoder for a media type.
+	Codec struct {
+		// MediaType is the canonical media type, e.g. "application/json".
+		MediaType string
+
+		// Aliases are additional media types, e.g. "text/xml".
+		Aliases []string
+
+		// Extensions are file extensions with leading dot, e.g. ".json".
+		Extensions []string
+
+		Encoder ICodecEncoder
+		Decoder ICodecDecoder
+	}
+
+	// Registry maps media types and file extensions to Codec.
+	Registry struct {
+		mu sync.RWMutex
+		codecs []Codec
+		byType map[string]int
+		byExt map[string]int
+	}
+
+	// MediaRange is an element of Accept header.
+	MediaRange struct {
+		Type string
+		Subtype string
+		Params map[string]string
+		Q float64
+	}
+)
+
+var (
+	ErrUnsupportedMediaType = errors.New("Unsupported media type")
+	ErrNotAcceptable = errors.New("Not acceptable")
+
+	// DefaultRegistry is the Registry used by package level functions.
+	// Subpackages (json, xml, gob, etc.) register themselves when they are imported.
+	DefaultRegistry = NewRegistry()
+)
+
+
+// NewRegistry creates a new empty Registry and returns a pointer to it.
+func NewRegistry() *Registry {
+	return &Registry{
+		codecs: make([]Codec, 0),
+		byType: map[string]int{},
+		byExt: map[string]int{},
+	}
+}
+
+// normalizeExtension returns lower case extension with leading dot.
+func normalizeExtension(ext string) string {
+	ext = strings.ToLower(ext)
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// Register registers c.
+// If a media type or an extension has already been registered,
+// it is overwritten by c.
+func (r *Registry) Register(c Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := len(r.codecs)
+	r.codecs = append(r.codecs, c)
+
+	r.byType[strings.ToLower(c.MediaType)] = i
+	for _, a := range c.Aliases {
+		r.byType[strings.ToLower(a)] = i
+	}
+	for _, e := range c.Extensions {
+		r.byExt[normalizeExtension(e)] = i
+	}
+}
+
+// Lookup returns Codec for contentType, which might have parameters
+// like "application/json; charset=utf-8".
+// If it is not registered, structured syntax suffix like "+json" is used.
+func (r *Registry) Lookup(contentType string) (Codec, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return Codec{}, fmt.Errorf("%w: %s: %v", ErrUnsupportedMediaType, contentType, err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if i, ok := r.byType[mediaType]; ok {
+		return r.codecs[i], nil
+	}
+
+	// e.g. "application/problem+json" -> "application/json"
+	if p := strings.LastIndex(mediaType, "+"); p >= 0 {
+		if s := strings.Index(mediaType, "/"); (s >= 0) && (s < p) {
+			if i, ok := r.byType[mediaType[:s+1] + mediaType[p+1:]]; ok {
+				return r.codecs[i], nil
+			}
+		}
+	}
+
+	return Codec{}, fmt.Errorf("%w: %s", ErrUnsupportedMediaType, mediaType)
+}
+
+// LookupExtension returns Codec for file extension ext like ".json".
+func (r *Registry) LookupExtension(ext string) (Codec, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if i, ok := r.byExt[normalizeExtension(ext)]; ok {
+		return r.codecs[i], nil
+	}
+
+	return Codec{}, fmt.Errorf("%w: extension %s", ErrUnsupportedMediaType, ext)
+}
+
+// Negotiate returns the most preferred Codec for Accept header.
+// If accept is empty, the first registered Codec is returned.
+// If no Codec is acceptable, ErrNotAcceptable is returned.
+func (r *Registry) Negotiate(accept string) (Codec, error) {
+	ranges := ParseAccept(accept)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(ranges) == 0 {
+		if len(r.codecs) == 0 {
+			return Codec{}, ErrNotAcceptable
+		}
+		return r.codecs[0], nil
+	}
+
+	best := -1
+	bestQ := 0.0
+	for i, c := range r.codecs {
+		if r.byType[strings.ToLower(c.MediaType)] != i {
+			// Overwritten by later registration
+			continue
+		}
+
+		types := append([]string{c.MediaType}, c.Aliases...)
+		for _, t := range types {
+			if q := quality(ranges, t); q > bestQ {
+				best = i
+				bestQ = q
+			}
+		}
+	}
+
+	if best < 0 {
+		return Codec{}, fmt.Errorf("%w: %s", ErrNotAcceptable, accept)
+	}
+	return r.codecs[best], nil
+}
+
+// quality returns q-value of mediaType by the most specific matching range.
+func quality(ranges []MediaRange, mediaType string) float64 {
+	t, s, _ := strings.Cut(strings.ToLower(mediaType), "/")
+
+	q := 0.0
+	specificity := -1
+	for _, m := range ranges {
+		sp := -1
+		switch {
+		case (m.Type == t) && (m.Subtype == s):
+			sp = 2
+		case (m.Type == t) && (m.Subtype == "*"):
+			sp = 1
+		case (m.Type == "*") && (m.Subtype == "*"):
+			sp = 0
+		}
+
+		if sp > specificity {
+			specificity = sp
+			q = m.Q
+		}
+	}
+
+	return q
+}
+
+// ParseAccept parses Accept header and returns media ranges
+// sorted by preference (q-value, then specificity).
+// Invalid elements are ignored.
+func ParseAccept(accept string) []MediaRange {
+	ranges := make([]MediaRange, 0)
+
+	for _, e := range strings.Split(accept, ",") {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+
+		mediaType, params, err := mime.ParseMediaType(e)
+		if err != nil {
+			continue
+		}
+
+		t, s, ok := strings.Cut(mediaType, "/")
+		if !ok {
+			// mime.ParseMediaType accepts "*", which is sometimes sent.
+			t, s = "*", "*"
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(v, 64)
+			if (err != nil) || (q < 0) || (q > 1) {
+				continue
+			}
+			delete(params, "q")
+		}
+
+		ranges = append(ranges, MediaRange{
+			Type: t,
+			Subtype: s,
+			Params: params,
+			Q: q,
+		})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].Q != ranges[j].Q {
+			return ranges[i].Q > ranges[j].Q
+		}
+		return ranges[i].specificity() > ranges[j].specificity()
+	})
+
+	return ranges
+}
+
+// specificity returns 2 for "type/subtype", 1 for "type/*", and 0 for "*/*".
+func (m MediaRange) specificity() int {
+	switch {
+	case m.Type == "*":
+		return 0
+	case m.Subtype == "*":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// String returns media range without q-value.
+func (m MediaRange) String() string {
+	return mime.FormatMediaType(m.Type + "/" + m.Subtype, m.Params)
+}
+
+// ParseContentType parses Content-Type header
+// and returns lower case media type and its parameters.
+func ParseContentType(contentType string) (string, map[string]string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", nil, fmt.Errorf("Fail to Parse Content-Type: %w", err)
+	}
+	return mediaType, params, nil
+}
+
+// Register registers c to DefaultRegistry.
+func Register(c Codec) {
+	DefaultRegistry.Register(c)
+}
+
+// Lookup returns Codec for contentType from DefaultRegistry.
+func Lookup(contentType string) (Codec, error) {
+	return DefaultRegistry.Lookup(contentType)
+}
+
+// LookupExtension returns Codec for file extension from DefaultRegistry.
+func LookupExtension(ext string) (Codec, error) {
+	return DefaultRegistry.LookupExtension(ext)
+}
+
+// Negotiate returns the most preferred Codec for Accept header
+// from DefaultRegistry.
+func Negotiate(accept string) (Codec, error) {
+	return DefaultRegistry.Negotiate(accept)
+}
@@ -0,0 +1,166 @@
+package encoding
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+type (
+	fakeCodec struct {
+		name string
+	}
+)
+
+func (_ fakeCodec) Encode(any) (io.Reader, error) {
+	return nil, nil
+}
+
+func (_ fakeCodec) Decode(io.Reader, any) error {
+	return nil
+}
+
+func newFakeRegistry() *Registry {
+	r := NewRegistry()
+	for _, c := range []Codec{
+		{
+			MediaType: "application/json",
+			Extensions: []string{".json"},
+			Encoder: fakeCodec{ name: "json" },
+			Decoder: fakeCodec{ name: "json" },
+		},
+		{
+			MediaType: "application/xml",
+			Aliases: []string{"text/xml"},
+			Extensions: []string{"xml"},
+			Encoder: fakeCodec{ name: "xml" },
+			Decoder: fakeCodec{ name: "xml" },
+		},
+		{
+			MediaType: "application/x-gob",
+			Extensions: []string{".gob"},
+			Encoder: fakeCodec{ name: "gob" },
+			Decoder: fakeCodec{ name: "gob" },
+		},
+	} {
+		r.Register(c)
+	}
+	return r
+}
+
+func TestRegistryLookup(t *testing.T){
+	r := newFakeRegistry()
+
+	tests := []struct {
+		contentType string
+		want string
+	}{
+		{ contentType: "application/json", want: "application/json" },
+		{ contentType: "Application/JSON; charset=utf-8", want: "application/json" },
+		{ contentType: "application/problem+json", want: "application/json" },
+		{ contentType: "text/xml", want: "application/xml" },
+		{ contentType: "application/x-gob", want: "application/x-gob" },
+	}
+
+	for _, test := range tests {
+		t.Run(test.contentType, func(t *testing.T){
+			c, err := r.Lookup(test.contentType)
+			if err != nil {
+				t.Errorf("Fail: %v\n", err)
+				return
+			}
+			if c.MediaType != test.want {
+				t.Errorf("Fail: %s (want %s)\n", c.MediaType, test.want)
+				return
+			}
+		})
+	}
+
+	if _, err := r.Lookup("text/plain"); !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Errorf("Must be ErrUnsupportedMediaType: %v\n", err)
+		return
+	}
+	if _, err := r.Lookup(";;"); !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Errorf("Must be ErrUnsupportedMediaType: %v\n", err)
+		return
+	}
+}
+
+func TestRegistryLookupExtension(t *testing.T){
+	r := newFakeRegistry()
+
+	for ext, want := range map[string]string{
+		".json": "application/json",
+		"JSON": "application/json",
+		".xml": "application/xml",
+		"gob": "application/x-gob",
+	} {
+		c, err := r.LookupExtension(ext)
+		if err != nil {
+			t.Errorf("Fail: %v\n", err)
+			return
+		}
+		if c.MediaType != want {
+			t.Errorf("Fail: %s (want %s)\n", c.MediaType, want)
+			return
+		}
+	}
+
+	if _, err := r.LookupExtension(".csv"); !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Errorf("Must be ErrUnsupportedMediaType: %v\n", err)
+		return
+	}
+}
+
+func TestRegistryNegotiate(t *testing.T){
+	r := newFakeRegistry()
+
+	tests := []struct {
+		accept string
+		want string
+	}{
+		{ accept: "", want: "application/json" },
+		{ accept: "*/*", want: "application/json" },
+		{ accept: "application/xml", want: "application/xml" },
+		{ accept: "text/html, text/*;q=0.5", want: "application/xml" },
+		{ accept: "application/json;q=0.5, application/x-gob", want: "application/x-gob" },
+		{ accept: "*/*;q=0.1, application/json;q=0, application/xml;q=0.2", want: "application/xml" },
+		{ accept: "*/*, application/json;q=0", want: "application/xml" },
+	}
+
+	for _, test := range tests {
+		t.Run(test.accept, func(t *testing.T){
+			c, err := r.Negotiate(test.accept)
+			if err != nil {
+				t.Errorf("Fail: %v\n", err)
+				return
+			}
+			if c.MediaType != test.want {
+				t.Errorf("Fail: %s (want %s)\n", c.MediaType, test.want)
+				return
+			}
+		})
+	}
+
+	if _, err := r.Negotiate("text/html"); !errors.Is(err, ErrNotAcceptable) {
+		t.Errorf("Must be ErrNotAcceptable: %v\n", err)
+		return
+	}
+}
+
+func TestParseAccept(t *testing.T){
+	ranges := ParseAccept("text/*;q=0.3, text/html;level=1, */*;q=0.1, text/plain;q=x, text/html;q=0.7")
+
+	want := []string{"text/html; level=1", "text/html", "text/*", "*/*"}
+	if len(ranges) != len(want) {
+		t.Errorf("Fail: %v\n", ranges)
+		return
+	}
+
+	for i := range ranges {
+		if ranges[i].String() != want[i] {
+			t.Errorf("Fail: %s (want %s)\n", ranges[i].String(), want[i])
+			return
+		}
+	}
+}
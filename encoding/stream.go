@@ -0,0 +1,89 @@
+package encoding
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+type (
+	// StreamEncoder writes a sequence of values one by one.
+	StreamEncoder struct {
+		enc IEncoder
+	}
+
+	// StreamDecoder[T] is an iterator, which reads a sequence of values one by one.
+	//
+	//	dec := encoding.DecodeStream[T](json.NewDecoder, r)
+	//	for dec.Next() {
+	//		v := dec.Value()
+	//	}
+	//	if err := dec.Err(); err != nil {
+	//	}
+	StreamDecoder[T any] struct {
+		dec IDecoder
+		value T
+		err error
+		done bool
+	}
+)
+
+
+// EncodeStream creates a new StreamEncoder writing to w and returns a pointer to it.
+// Values are written to w without buffering all of them.
+// The encoding/json.NewEncoder in standard library writes JSON Lines.
+func EncodeStream[E IEncoder](newEncoder func(io.Writer) E, w io.Writer) *StreamEncoder {
+	return &StreamEncoder{ enc: newEncoder(w) }
+}
+
+// Encode encodes data and writes it to underlying io.Writer.
+func (s *StreamEncoder) Encode(data any) error {
+	if err := s.enc.Encode(data); err != nil {
+		return fmt.Errorf("Fail to Encode: %w", err)
+	}
+	return nil
+}
+
+// DecodeStream[T] creates a new StreamDecoder[T] reading from r
+// and returns a pointer to it.
+// The encoding/json.NewDecoder in standard library can read JSON Lines.
+func DecodeStream[T any, D IDecoder](newDecoder func(io.Reader) D, r io.Reader) *StreamDecoder[T] {
+	return &StreamDecoder[T]{ dec: newDecoder(r) }
+}
+
+// Next decodes and validates the next value.
+// It returns false at the end of stream or when an error occurs.
+func (s *StreamDecoder[T]) Next() bool {
+	if s.done {
+		return false
+	}
+
+	var v T
+	if err := s.dec.Decode(&v); err != nil {
+		s.done = true
+		if !errors.Is(err, io.EOF) {
+			s.err = fmt.Errorf("Fail to Decode: %w", err)
+		}
+		return false
+	}
+
+	if err := Validate(&v); err != nil {
+		s.done = true
+		s.err = fmt.Errorf("Fail to Validate: %w", err)
+		return false
+	}
+
+	s.value = v
+	return true
+}
+
+// Value returns the value decoded by the last Next.
+func (s *StreamDecoder[T]) Value() T {
+	return s.value
+}
+
+// Err returns the error occured during Next.
+// The end of stream is not an error.
+func (s *StreamDecoder[T]) Err() error {
+	return s.err
+}
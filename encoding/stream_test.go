@@ -0,0 +1,120 @@
+package encoding
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+
+func TestStream(t *testing.T){
+	type (
+		A struct {
+			A1 string `json:"a1" xml:"a1"`
+			A2 uint64 `json:"a2" xml:"a2"`
+		}
+	)
+
+	tests := []struct {
+		name string
+		enc func(io.Writer) *StreamEncoder
+		dec func(io.Reader) *StreamDecoder[A]
+	}{
+		{
+			name: "JSON",
+			enc: func(w io.Writer) *StreamEncoder {
+				return EncodeStream(json.NewEncoder, w)
+			},
+			dec: func(r io.Reader) *StreamDecoder[A] {
+				return DecodeStream[A](json.NewDecoder, r)
+			},
+		},
+		{
+			name: "XML",
+			enc: func(w io.Writer) *StreamEncoder {
+				return EncodeStream(xml.NewEncoder, w)
+			},
+			dec: func(r io.Reader) *StreamDecoder[A] {
+				return DecodeStream[A](xml.NewDecoder, r)
+			},
+		},
+		{
+			name: "Gob",
+			enc: func(w io.Writer) *StreamEncoder {
+				return EncodeStream(gob.NewEncoder, w)
+			},
+			dec: func(r io.Reader) *StreamDecoder[A] {
+				return DecodeStream[A](gob.NewDecoder, r)
+			},
+		},
+	}
+
+	values := []A{
+		{ A1: "aaaa", A2: 12345 },
+		{ A1: "bbbb", A2: 0 },
+		{ A1: "--x-c-909", A2: 78998765 },
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T){
+			buf := bytes.NewBuffer([]byte{})
+
+			enc := test.enc(buf)
+			for _, v := range values {
+				if err := enc.Encode(v); err != nil {
+					t.Errorf("Fail: %v\n", err)
+					return
+				}
+			}
+
+			dec := test.dec(buf)
+			i := 0
+			for dec.Next() {
+				if i >= len(values) {
+					t.Errorf("Too many values\n")
+					return
+				}
+				if v := dec.Value(); v != values[i] {
+					t.Errorf("Fail: %v != %v\n", v, values[i])
+					return
+				}
+				i += 1
+			}
+
+			if err := dec.Err(); err != nil {
+				t.Errorf("Fail: %v\n", err)
+				return
+			}
+			if i != len(values) {
+				t.Errorf("Too few values: %d\n", i)
+				return
+			}
+		})
+	}
+}
+
+func TestStreamError(t *testing.T){
+	dec := DecodeStream[int](json.NewDecoder, strings.NewReader("1\n2\nx\n4\n"))
+
+	n := 0
+	for dec.Next() {
+		n += 1
+	}
+
+	if n != 2 {
+		t.Errorf("Must stop at invalid value: %d\n", n)
+		return
+	}
+	if dec.Err() == nil {
+		t.Errorf("Must Fail\n")
+		return
+	}
+	if dec.Next() {
+		t.Errorf("Must not continue after error\n")
+		return
+	}
+}
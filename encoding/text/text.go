@@ -0,0 +1,127 @@
+// Package encoding/text implements Encoder/Decoder for plain text
+//
+// Supported data are string, []byte, encoding.TextMarshaler and fmt.Stringer
+// (and pointers to them). Nil pointer is rejected with ErrNilPointer.
+// Decoder supports *string, *[]byte, *any and encoding.TextUnmarshaler.
+package text
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	ymdenc "github.com/ymd-h/go/encoding"
+)
+
+type (
+	Encoder struct {}
+	Decoder struct {}
+
+	// encoder writes text to io.Writer.
+	encoder struct {
+		w io.Writer
+	}
+
+	// decoder reads all text from io.Reader.
+	decoder struct {
+		r io.Reader
+	}
+)
+
+const (
+	// MediaType is registered to encoding.DefaultRegistry on import.
+	MediaType = "text/plain"
+)
+
+var (
+	ErrUnsupportedType = errors.New("Unsupported type")
+	ErrNilPointer = errors.New("Nil pointer")
+)
+
+
+func init(){
+	ymdenc.Register(ymdenc.Codec{
+		MediaType: MediaType,
+		Extensions: []string{".txt"},
+		Encoder: Encoder{},
+		Decoder: Decoder{},
+	})
+}
+
+func newEncoder(w io.Writer) encoder {
+	return encoder{ w: w }
+}
+
+func newDecoder(r io.Reader) decoder {
+	return decoder{ r: r }
+}
+
+// Encode encodes data and returns encoded io.Reader.
+func (_ Encoder) Encode(data any) (io.Reader, error) {
+	return ymdenc.Encode(newEncoder, data)
+}
+
+// EncodeTo encodes data and writes it to w without intermediate buffer.
+func (_ Encoder) EncodeTo(w io.Writer, data any) error {
+	return ymdenc.EncodeTo(newEncoder, w, data)
+}
+
+// Decode decodes buf io.Reader to ptr.
+func (_ Decoder) Decode(buf io.Reader, ptr any) error {
+	return ymdenc.Decode(newDecoder, buf, ptr)
+}
+
+func (e encoder) Encode(data any) error {
+	// Typed nil is not caught by nil check of encoding.Encode.
+	if v := reflect.ValueOf(data); (v.Kind() == reflect.Pointer) && v.IsNil() {
+		return fmt.Errorf("%w: %T", ErrNilPointer, data)
+	}
+
+	var b []byte
+	switch v := data.(type) {
+	case string:
+		b = []byte(v)
+	case *string:
+		b = []byte(*v)
+	case []byte:
+		b = v
+	case *[]byte:
+		b = *v
+	case encoding.TextMarshaler:
+		var err error
+		b, err = v.MarshalText()
+		if err != nil {
+			return err
+		}
+	case fmt.Stringer:
+		b = []byte(v.String())
+	default:
+		return fmt.Errorf("%w: %T", ErrUnsupportedType, data)
+	}
+
+	_, err := e.w.Write(b)
+	return err
+}
+
+func (d decoder) Decode(ptr any) error {
+	b, err := io.ReadAll(d.r)
+	if err != nil {
+		return err
+	}
+
+	switch p := ptr.(type) {
+	case *string:
+		*p = string(b)
+	case *[]byte:
+		*p = b
+	case *any:
+		*p = string(b)
+	case encoding.TextUnmarshaler:
+		return p.UnmarshalText(b)
+	default:
+		return fmt.Errorf("%w: %T", ErrUnsupportedType, ptr)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package text
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ymd-h/go/encoding"
+)
+
+func TestText(t *testing.T){
+	enc := Encoder{}
+	dec := Decoder{}
+
+	for _, data := range []any{ "abc", []byte("abc"), net.IPv4(1, 2, 3, 4) } {
+		b, err := enc.Encode(data)
+		if err != nil {
+			t.Errorf("Fail: %v\n", err)
+			return
+		}
+
+		var s string
+		if err := dec.Decode(b, &s); err != nil {
+			t.Errorf("Fail: %v\n", err)
+			return
+		}
+
+		if (s != "abc") && (s != "1.2.3.4") {
+			t.Errorf("Fail: %s\n", s)
+			return
+		}
+	}
+
+	b, err := enc.Encode("5.6.7.8")
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	var ip net.IP
+	if err := dec.Decode(b, &ip); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if ip.String() != "5.6.7.8" {
+		t.Errorf("Fail: %v\n", ip)
+		return
+	}
+
+	if _, err := enc.Encode(1); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Must be ErrUnsupportedType: %v\n", err)
+		return
+	}
+
+	for _, data := range []any{ (*string)(nil), (*[]byte)(nil), (*time.Time)(nil) } {
+		if _, err := enc.Encode(data); !errors.Is(err, ErrNilPointer) {
+			t.Errorf("Must be ErrNilPointer: %T %v\n", data, err)
+			return
+		}
+	}
+
+	var i int
+	b, _ = enc.Encode("1")
+	if err := dec.Decode(b, &i); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Must be ErrUnsupportedType: %v\n", err)
+		return
+	}
+}
+
+func TestRegister(t *testing.T){
+	c, err := encoding.Lookup("text/plain; charset=utf-8")
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if c.MediaType != MediaType {
+		t.Errorf("Fail: %s\n", c.MediaType)
+		return
+	}
+
+	c, err = encoding.LookupExtension(".txt")
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if _, ok := c.Decoder.(Decoder); !ok {
+		t.Errorf("Fail: %T\n", c.Decoder)
+		return
+	}
+}
@@ -0,0 +1,59 @@
+// Package encoding/toml implements Encoder/Decoder for TOML
+package toml
+
+import (
+	"io"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ymd-h/go/encoding"
+)
+
+type (
+	Encoder struct {}
+	Decoder struct {}
+
+	// decoder adapts toml.Decoder to encoding.IDecoder.
+	decoder struct {
+		d *toml.Decoder
+	}
+)
+
+const (
+	// MediaType is registered to encoding.DefaultRegistry on import.
+	MediaType = "application/toml"
+)
+
+func init(){
+	encoding.Register(encoding.Codec{
+		MediaType: MediaType,
+		Extensions: []string{".toml"},
+		Encoder: Encoder{},
+		Decoder: Decoder{},
+	})
+}
+
+func newDecoder(r io.Reader) decoder {
+	return decoder{ d: toml.NewDecoder(r) }
+}
+
+// Decode decodes to v, and discards toml.MetaData.
+func (d decoder) Decode(v any) error {
+	_, err := d.d.Decode(v)
+	return err
+}
+
+// Encode encodes data and returns encoded io.Reader.
+// TOML document must be a table, so that data must be a struct or a map.
+func (_ Encoder) Encode(data any) (io.Reader, error) {
+	return encoding.Encode(toml.NewEncoder, data)
+}
+
+// EncodeTo encodes data and writes it to w without intermediate buffer.
+func (_ Encoder) EncodeTo(w io.Writer, data any) error {
+	return encoding.EncodeTo(toml.NewEncoder, w, data)
+}
+
+// Decode decodes buf io.Reader to ptr.
+func (_ Decoder) Decode(buf io.Reader, ptr any) error {
+	return encoding.Decode(newDecoder, buf, ptr)
+}
@@ -0,0 +1,359 @@
+package encoding
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+type (
+	// IValidator is interface for custom validation.
+	// Validate() is called after struct tag rules.
+	IValidator interface {
+		Validate() error
+	}
+
+	// ValidationError is a validation failure at a field.
+	ValidationError struct {
+		// Path is field path like "A3.B2[1]". Empty path means the root.
+		Path string
+
+		// Rule is failed rule like "min", or "Validate" for IValidator.
+		Rule string
+
+		Err error
+	}
+
+	// ValidationErrors is a list of ValidationError.
+	ValidationErrors []*ValidationError
+
+	rule struct {
+		name string
+		param string
+	}
+
+	validator struct {
+		errs ValidationErrors
+		visited map[uintptr]bool
+	}
+)
+
+var (
+	ErrValidation = errors.New("Validation failed")
+	ErrInvalidRule = errors.New("Invalid validation rule")
+
+	iValidatorType = reflect.TypeOf((*IValidator)(nil)).Elem()
+
+	regexpCache sync.Map
+)
+
+
+func (e *ValidationError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "(root)"
+	}
+	return fmt.Sprintf("%s: %s: %v", path, e.Rule, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Is returns true for ErrValidation.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+func (e ValidationErrors) Error() string {
+	s := make([]string, 0, len(e))
+	for _, v := range e {
+		s = append(s, v.Error())
+	}
+	return strings.Join(s, "; ")
+}
+
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, v := range e {
+		errs = append(errs, v)
+	}
+	return errs
+}
+
+
+// Validate validates v by `validate` struct tags and IValidator recursively.
+// Fields can have comma separated rules like `validate:"required,min=1"`.
+//
+//   - required: must not be zero value (nil for pointer).
+//   - min=N / max=N: bounds of number, or length of string, slice and map.
+//   - enum=a|b|c: string representation must be one of them.
+//   - regex=RE: string must match RE. Since RE can have commas,
+//     it must be the last rule.
+//
+// Except required, rules are skipped for nil pointer.
+// If any rule fails, ValidationErrors is returned,
+// which matches ErrValidation with errors.Is.
+// If a rule is malformed, ErrInvalidRule is returned.
+//
+// Decode calls Validate automatically, so that every codec validates.
+func Validate(v any) error {
+	va := &validator{
+		errs: make(ValidationErrors, 0),
+		visited: map[uintptr]bool{},
+	}
+
+	if err := va.walk("", reflect.ValueOf(v)); err != nil {
+		return err
+	}
+
+	if len(va.errs) > 0 {
+		return va.errs
+	}
+	return nil
+}
+
+// parseRules parses `validate` struct tag.
+func parseRules(tag string) []rule {
+	rules := make([]rule, 0)
+	for tag != "" {
+		var s string
+		if strings.HasPrefix(tag, "regex=") {
+			s, tag = tag, ""
+		} else {
+			s, tag, _ = strings.Cut(tag, ",")
+		}
+
+		name, param, _ := strings.Cut(strings.TrimSpace(s), "=")
+		if name != "" {
+			rules = append(rules, rule{ name: name, param: param })
+		}
+	}
+	return rules
+}
+
+// needsWalk returns true if the value of t might have something to validate.
+func needsWalk(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Pointer, reflect.Interface,
+		reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	default:
+		return t.Implements(iValidatorType) ||
+			reflect.PointerTo(t).Implements(iValidatorType)
+	}
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func (va *validator) fail(path, rule string, err error) {
+	va.errs = append(va.errs, &ValidationError{ Path: path, Rule: rule, Err: err })
+}
+
+func (va *validator) walk(path string, v reflect.Value) error {
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		p := v.Pointer()
+		if va.visited[p] {
+			return nil
+		}
+		va.visited[p] = true
+		return va.walk(path, v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return va.walk(path, v.Elem())
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+
+			fp := joinPath(path, f.Name)
+			fv := v.Field(i)
+			for _, r := range parseRules(f.Tag.Get("validate")) {
+				if err := va.apply(fp, r, fv); err != nil {
+					return err
+				}
+			}
+
+			if needsWalk(f.Type) {
+				if err := va.walk(fp, fv); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if needsWalk(v.Type().Elem()) {
+			for i := 0; i < v.Len(); i++ {
+				if err := va.walk(fmt.Sprintf("%s[%d]", path, i), v.Index(i)); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Map:
+		if needsWalk(v.Type().Elem()) {
+			iter := v.MapRange()
+			for iter.Next() {
+				if err := va.walk(fmt.Sprintf("%s[%v]", path, iter.Key()), iter.Value()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	var val IValidator
+	if v.CanAddr() && v.Addr().Type().Implements(iValidatorType) {
+		val = v.Addr().Interface().(IValidator)
+	} else if v.Type().Implements(iValidatorType) && v.CanInterface() {
+		val = v.Interface().(IValidator)
+	}
+	if val != nil {
+		if err := val.Validate(); err != nil {
+			va.fail(path, "Validate", err)
+		}
+	}
+
+	return nil
+}
+
+// apply applies r to v.
+// Only malformed rule is returned as error.
+func (va *validator) apply(path string, r rule, v reflect.Value) error {
+	if r.name == "required" {
+		if v.IsZero() {
+			va.fail(path, r.name, errors.New("Must not be zero"))
+		}
+		return nil
+	}
+
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	ok := true
+	var err error
+	switch r.name {
+	case "min":
+		ok, err = compare(v, r.param, func(c int) bool { return c >= 0 })
+	case "max":
+		ok, err = compare(v, r.param, func(c int) bool { return c <= 0 })
+	case "enum":
+		s := fmt.Sprint(v.Interface())
+		ok = false
+		for _, e := range strings.Split(r.param, "|") {
+			if s == e {
+				ok = true
+				break
+			}
+		}
+	case "regex":
+		if v.Kind() != reflect.String {
+			err = fmt.Errorf("regex for %s", v.Type())
+			break
+		}
+		var re *regexp.Regexp
+		re, err = compileRegexp(r.param)
+		if err == nil {
+			ok = re.MatchString(v.String())
+		}
+	default:
+		err = fmt.Errorf("unknown rule %s", r.name)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %s: %s=%s: %v", ErrInvalidRule, path, r.name, r.param, err)
+	}
+	if !ok {
+		va.fail(path, r.name, fmt.Errorf("Must satisfy %s=%s", r.name, r.param))
+	}
+	return nil
+}
+
+// order returns -1, 0 or 1 like strings.Compare.
+func order[T int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compare compares v with param, and returns ok(order(v, param)).
+// For string, slice, array and map, their length are compared.
+func compare(v reflect.Value, param string, ok func(int) bool) (bool, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		p, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return false, err
+		}
+		return ok(order(v.Int(), p)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		p, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			return false, err
+		}
+		return ok(order(v.Uint(), p)), nil
+	case reflect.Float32, reflect.Float64:
+		p, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return false, err
+		}
+		return ok(order(v.Float(), p)), nil
+	case reflect.String:
+		p, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return false, err
+		}
+		return ok(order(int64(utf8.RuneCountInString(v.String())), p)), nil
+	case reflect.Slice, reflect.Array, reflect.Map:
+		p, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return false, err
+		}
+		return ok(order(int64(v.Len()), p)), nil
+	default:
+		return false, fmt.Errorf("not comparable %s", v.Type())
+	}
+}
+
+// compileRegexp compiles expr with cache.
+func compileRegexp(expr string) (*regexp.Regexp, error) {
+	if re, ok := regexpCache.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	regexpCache.Store(expr, re)
+	return re, nil
+}
@@ -0,0 +1,134 @@
+package encoding
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type (
+	validated struct {
+		Name string `validate:"required,min=2,max=5"`
+		Age int `validate:"min=0,max=150"`
+		Kind string `validate:"enum=a|b|c"`
+		Code string `validate:"regex=^[a-z]{2,3}$"`
+		Tags []string `validate:"max=2"`
+		Child *child
+		Children []child
+	}
+
+	child struct {
+		Score *float64 `validate:"min=0.5"`
+	}
+)
+
+func (c child) Validate() error {
+	if (c.Score != nil) && (*c.Score > 10) {
+		return errors.New("Too large score")
+	}
+	return nil
+}
+
+func TestValidate(t *testing.T){
+	f := func(v float64) *float64 { return &v }
+
+	ok := validated{
+		Name: "abc",
+		Age: 20,
+		Kind: "b",
+		Code: "xyz",
+		Tags: []string{"t"},
+		Child: &child{ Score: f(1) },
+		Children: []child{ {}, { Score: f(0.5) } },
+	}
+	if err := Validate(&ok); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	ng := validated{
+		Name: "",
+		Age: -1,
+		Kind: "d",
+		Code: "xyz0",
+		Tags: []string{"a", "b", "c"},
+		Child: &child{ Score: f(0.1) },
+		Children: []child{ {}, { Score: f(11) } },
+	}
+	err := Validate(ng)
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("Must be ErrValidation: %v\n", err)
+		return
+	}
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Errorf("Must be ValidationErrors: %T\n", err)
+		return
+	}
+
+	want := map[string]string{
+		"Name": "required",
+		"Age": "min",
+		"Kind": "enum",
+		"Code": "regex",
+		"Tags": "max",
+		"Child.Score": "min",
+		"Children[1]": "Validate",
+	}
+	got := map[string]string{}
+	for _, e := range errs {
+		if _, ok := got[e.Path]; !ok {
+			got[e.Path] = e.Rule
+		}
+	}
+	for p, r := range want {
+		if got[p] != r {
+			t.Errorf("Fail: %s: %s (want %s)\n%v\n", p, got[p], r, err)
+			return
+		}
+	}
+}
+
+func TestValidateInvalidRule(t *testing.T){
+	for _, v := range []any{
+		&struct { A bool `validate:"min=1"` }{},
+		&struct { A int `validate:"min=x"` }{},
+		&struct { A int `validate:"regex=a"` }{},
+		&struct { A string `validate:"regex=("` }{},
+		&struct { A string `validate:"unknown"` }{},
+	} {
+		if err := Validate(v); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("Must be ErrInvalidRule: %T: %v\n", v, err)
+			return
+		}
+	}
+}
+
+func TestDecodeValidate(t *testing.T){
+	var v validated
+	err := Decode(json.NewDecoder, strings.NewReader(`{"Name": "a"}`), &v)
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("Must be ErrValidation: %v\n", err)
+		return
+	}
+
+	dec := DecodeStream[validated](json.NewDecoder, io.MultiReader(
+		strings.NewReader(`{"Name": "abc", "Kind": "a", "Code": "ab"}`),
+		strings.NewReader(`{"Name": "abc", "Kind": "x", "Code": "ab"}`),
+	))
+	if !dec.Next() {
+		t.Errorf("Fail: %v\n", dec.Err())
+		return
+	}
+	if dec.Next() {
+		t.Errorf("Must Fail\n")
+		return
+	}
+	if !errors.Is(dec.Err(), ErrValidation) {
+		t.Errorf("Must be ErrValidation: %v\n", dec.Err())
+		return
+	}
+}
@@ -9,16 +9,147 @@ import (
 )
 
 type (
-	Encoder struct {}
-	Decoder struct {}
+	// Encoder encodes XML.
+	// The zero value encodes with the defaults of encoding/xml.
+	// Use NewEncoder to configure it.
+	Encoder struct {
+		prefix string
+		indent string
+	}
+
+	// EncoderOption configures Encoder.
+	EncoderOption func(*Encoder)
+
+	// Decoder decodes XML.
+	// The zero value decodes with the defaults of encoding/xml.
+	// Use NewDecoder to configure it.
+	Decoder struct {
+		nonStrict bool
+		autoClose []string
+		charsetReader func(charset string, input io.Reader) (io.Reader, error)
+		maxInputSize int64
+	}
+
+	// DecoderOption configures Decoder.
+	DecoderOption func(*Decoder)
+)
+
+const (
+	// MediaType is registered to encoding.DefaultRegistry on import.
+	MediaType = "application/xml"
 )
 
+func init(){
+	encoding.Register(encoding.Codec{
+		MediaType: MediaType,
+		Aliases: []string{"text/xml"},
+		Extensions: []string{".xml"},
+		Encoder: Encoder{},
+		Decoder: Decoder{},
+	})
+}
+
+// NewEncoder creates a new Encoder with options.
+func NewEncoder(options ...EncoderOption) Encoder {
+	var e Encoder
+	for _, o := range options {
+		o(&e)
+	}
+	return e
+}
+
+// Indent makes Encoder write indented XML like xml.MarshalIndent.
+func Indent(prefix, indent string) EncoderOption {
+	return func(e *Encoder){
+		e.prefix = prefix
+		e.indent = indent
+	}
+}
+
+func (e Encoder) newEncoder(w io.Writer) *xml.Encoder {
+	enc := xml.NewEncoder(w)
+	enc.Indent(e.prefix, e.indent)
+	return enc
+}
+
 // Encode encodes data and returns encoded io.Reader.
-func (_ Encoder) Encode(data any) (io.Reader, error) {
-	return encoding.Encode(xml.NewEncoder, data)
+func (e Encoder) Encode(data any) (io.Reader, error) {
+	return encoding.Encode(e.newEncoder, data)
+}
+
+// EncodeTo encodes data and writes it to w without intermediate buffer.
+func (e Encoder) EncodeTo(w io.Writer, data any) error {
+	return encoding.EncodeTo(e.newEncoder, w, data)
+}
+
+// NewDecoder creates a new Decoder with options.
+func NewDecoder(options ...DecoderOption) Decoder {
+	var d Decoder
+	for _, o := range options {
+		o(&d)
+	}
+	return d
+}
+
+// Strict sets xml.Decoder.Strict. The default is true.
+// When strict is false, autoClose is used as xml.Decoder.AutoClose,
+// e.g. xml.HTMLAutoClose.
+func Strict(strict bool, autoClose ...string) DecoderOption {
+	return func(d *Decoder){
+		d.nonStrict = !strict
+		d.autoClose = autoClose
+	}
+}
+
+// CharsetReader sets xml.Decoder.CharsetReader,
+// which converts non UTF-8 input to UTF-8.
+func CharsetReader(f func(charset string, input io.Reader) (io.Reader, error)) DecoderOption {
+	return func(d *Decoder){
+		d.charsetReader = f
+	}
+}
+
+// MaxInputSize makes Decoder fail with encoding.ErrInputTooLarge
+// when input is larger than n bytes.
+func MaxInputSize(n int64) DecoderOption {
+	return func(d *Decoder){
+		d.maxInputSize = n
+	}
+}
+
+func (d Decoder) newDecoder(r io.Reader) *xml.Decoder {
+	if d.maxInputSize > 0 {
+		r = encoding.LimitReader(r, d.maxInputSize)
+	}
+
+	dec := xml.NewDecoder(r)
+	if d.nonStrict {
+		dec.Strict = false
+		dec.AutoClose = d.autoClose
+	}
+	dec.CharsetReader = d.charsetReader
+
+	return dec
 }
 
 // Decode decodes buf io.Reader to ptr.
-func (_ Decoder) Decode(buf io.Reader, ptr any) error {
-	return encoding.Decode(xml.NewDecoder, buf, ptr)
+func (d Decoder) Decode(buf io.Reader, ptr any) error {
+	return encoding.Decode(d.newDecoder, buf, ptr)
+}
+
+// EncodeStream creates a new StreamEncoder writing XML elements to w.
+func EncodeStream(w io.Writer) *encoding.StreamEncoder {
+	return encoding.EncodeStream(xml.NewEncoder, w)
+}
+
+// DecodeStream[T] creates a new StreamDecoder[T] reading XML elements from r.
+func DecodeStream[T any](r io.Reader) *encoding.StreamDecoder[T] {
+	return DecodeStreamWith[T](Decoder{}, r)
+}
+
+// DecodeStreamWith[T] creates a new StreamDecoder[T] reading XML elements
+// from r with options of d.
+// MaxInputSize limits the whole stream.
+func DecodeStreamWith[T any](d Decoder, r io.Reader) *encoding.StreamDecoder[T] {
+	return encoding.DecodeStream[T](d.newDecoder, r)
 }
@@ -1,8 +1,12 @@
 package xml
 
 import (
+	"errors"
+	"io"
+	"strings"
 	"testing"
 
+	"github.com/ymd-h/go/encoding"
 	"github.com/ymd-h/go/slices"
 )
 
@@ -55,3 +59,99 @@ func TestXML(t *testing.T){
 	}
 
 }
+
+func TestRegister(t *testing.T){
+	c, err := encoding.Lookup("text/xml")
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if c.MediaType != MediaType {
+		t.Errorf("Fail: %s\n", c.MediaType)
+		return
+	}
+
+	c, err = encoding.LookupExtension(".xml")
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if _, ok := c.Decoder.(Decoder); !ok {
+		t.Errorf("Fail: %T\n", c.Decoder)
+		return
+	}
+}
+
+func TestDecoderOptions(t *testing.T){
+	type A struct {
+		A1 string `xml:"a1"`
+	}
+
+	input := `<A><a1>abc</a1><br></A>`
+
+	var a A
+	if err := (Decoder{}).Decode(strings.NewReader(input), &a); err == nil {
+		t.Errorf("Must Fail\n")
+		return
+	}
+
+	dec := NewDecoder(Strict(false, "br"))
+	if err := dec.Decode(strings.NewReader(input), &a); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if a.A1 != "abc" {
+		t.Errorf("Fail: %s\n", a.A1)
+		return
+	}
+
+	latin1 := `<?xml version="1.0" encoding="ISO-8859-1"?><A><a1>abc</a1></A>`
+	if err := (Decoder{}).Decode(strings.NewReader(latin1), &a); err == nil {
+		t.Errorf("Must Fail\n")
+		return
+	}
+
+	called := false
+	dec = NewDecoder(CharsetReader(func(charset string, input io.Reader) (io.Reader, error){
+		called = (charset == "ISO-8859-1")
+		return input, nil
+	}))
+	if err := dec.Decode(strings.NewReader(latin1), &a); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if !called {
+		t.Errorf("Fail: CharsetReader is not called\n")
+		return
+	}
+
+	dec = NewDecoder(MaxInputSize(10))
+	err := dec.Decode(strings.NewReader(input), &a)
+	if !errors.Is(err, encoding.ErrInputTooLarge) {
+		t.Errorf("Must be ErrInputTooLarge: %v\n", err)
+		return
+	}
+}
+
+func TestEncoderOptions(t *testing.T){
+	type A struct {
+		A1 string `xml:"a1"`
+	}
+
+	r, err := NewEncoder(Indent("", "  ")).Encode(A{ A1: "abc" })
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	if want := "<A>\n  <a1>abc</a1>\n</A>"; string(b) != want {
+		t.Errorf("Fail: %q (want %q)\n", string(b), want)
+		return
+	}
+}
@@ -0,0 +1,54 @@
+// Package encoding/yaml implements Encoder/Decoder for YAML
+package yaml
+
+import (
+	"io"
+
+	"github.com/ymd-h/go/encoding"
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	Encoder struct {}
+	Decoder struct {}
+)
+
+const (
+	// MediaType is registered to encoding.DefaultRegistry on import.
+	MediaType = "application/yaml"
+)
+
+func init(){
+	encoding.Register(encoding.Codec{
+		MediaType: MediaType,
+		Aliases: []string{"application/x-yaml", "text/yaml"},
+		Extensions: []string{".yaml", ".yml"},
+		Encoder: Encoder{},
+		Decoder: Decoder{},
+	})
+}
+
+// Encode encodes data and returns encoded io.Reader.
+func (_ Encoder) Encode(data any) (io.Reader, error) {
+	return encoding.Encode(yaml.NewEncoder, data)
+}
+
+// EncodeTo encodes data and writes it to w without intermediate buffer.
+func (_ Encoder) EncodeTo(w io.Writer, data any) error {
+	return encoding.EncodeTo(yaml.NewEncoder, w, data)
+}
+
+// Decode decodes buf io.Reader to ptr.
+func (_ Decoder) Decode(buf io.Reader, ptr any) error {
+	return encoding.Decode(yaml.NewDecoder, buf, ptr)
+}
+
+// EncodeStream creates a new StreamEncoder writing YAML documents to w.
+func EncodeStream(w io.Writer) *encoding.StreamEncoder {
+	return encoding.EncodeStream(yaml.NewEncoder, w)
+}
+
+// DecodeStream[T] creates a new StreamDecoder[T] reading YAML documents from r.
+func DecodeStream[T any](r io.Reader) *encoding.StreamDecoder[T] {
+	return encoding.DecodeStream[T](yaml.NewDecoder, r)
+}
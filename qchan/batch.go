@@ -0,0 +1,218 @@
+package qchan
+
+import (
+	"time"
+)
+
+type (
+	// ISource[T] is interface for source of Adapter[V].
+	// Queue[T] and Subscription[T] implement it.
+	ISource[T any] interface {
+		Out() <- chan T
+		Error() error
+	}
+
+	// Adapter[V] transforms output channel of source.
+	// It stops when output channel of source is closed.
+	Adapter[V any] struct {
+		out <- chan V
+		done <- chan struct{}
+		err func() error
+	}
+)
+
+
+// newAdapter[T, V] creates a new Adapter[V] and returns a pointer to it.
+// run must return when source output channel is closed,
+// and then remained values are put by flush.
+func newAdapter[T, V any](src ISource[T], run func(out chan <- V), flush func(out chan <- V)) *Adapter[V] {
+	out := make(chan V, 0)
+	done := make(chan struct{})
+
+	go func(){
+		defer close(out)
+		run(out)
+		close(done)
+		flush(out)
+	}()
+
+	return &Adapter[V]{ out: out, done: done, err: src.Error }
+}
+
+// NewBatch[T] creates a new Adapter[[]T], which puts values as a batch
+// when the batch reaches size or when linger has passed
+// since the first value of the batch is received.
+// If size is not positive, the batch size is unlimited.
+// If linger is not positive, the batch waits until it reaches size.
+func NewBatch[T any](src ISource[T], size int, linger time.Duration) *Adapter[[]T] {
+	batch := make([]T, 0)
+
+	return newAdapter[T, []T](
+		src,
+		func(out chan <- []T){
+			var timer *time.Timer
+			var timeout <- chan time.Time
+
+			flush := func(){
+				if timer != nil {
+					timer.Stop()
+					timer = nil
+					timeout = nil
+				}
+				out <- batch
+				batch = make([]T, 0)
+			}
+
+			for {
+				select {
+				case v, ok := <- src.Out():
+					if !ok {
+						if timer != nil {
+							timer.Stop()
+						}
+						return
+					}
+
+					batch = append(batch, v)
+					if (len(batch) == 1) && (linger > 0) {
+						timer = time.NewTimer(linger)
+						timeout = timer.C
+					}
+					if (size > 0) && (len(batch) >= size) {
+						flush()
+					}
+				case <- timeout:
+					flush()
+				}
+			}
+		},
+		func(out chan <- []T){
+			if len(batch) > 0 {
+				out <- batch
+			}
+		},
+	)
+}
+
+// NewWindow[T] creates a new Adapter[[]T], which puts values
+// received in every fixed time window of d.
+// Empty window is not put.
+func NewWindow[T any](src ISource[T], d time.Duration) *Adapter[[]T] {
+	window := make([]T, 0)
+
+	return newAdapter[T, []T](
+		src,
+		func(out chan <- []T){
+			ticker := time.NewTicker(d)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case v, ok := <- src.Out():
+					if !ok {
+						return
+					}
+					window = append(window, v)
+				case <- ticker.C:
+					if len(window) > 0 {
+						out <- window
+						window = make([]T, 0)
+					}
+				}
+			}
+		},
+		func(out chan <- []T){
+			if len(window) > 0 {
+				out <- window
+			}
+		},
+	)
+}
+
+// NewDebounce[T] creates a new Adapter[T], which puts only the last value
+// after no value is received for d.
+func NewDebounce[T any](src ISource[T], d time.Duration) *Adapter[T] {
+	var last T
+	pending := false
+
+	return newAdapter[T, T](
+		src,
+		func(out chan <- T){
+			timer := time.NewTimer(d)
+			timer.Stop()
+			defer timer.Stop()
+
+			for {
+				select {
+				case v, ok := <- src.Out():
+					if !ok {
+						return
+					}
+					last = v
+					pending = true
+
+					// Timer might fire before Stop, so that we drain it.
+					if !timer.Stop() {
+						select {
+						case <- timer.C:
+						default:
+						}
+					}
+					timer.Reset(d)
+				case <- timer.C:
+					if pending {
+						out <- last
+						pending = false
+					}
+				}
+			}
+		},
+		func(out chan <- T){
+			if pending {
+				out <- last
+			}
+		},
+	)
+}
+
+// NewThrottle[T] creates a new Adapter[T], which puts at most one value
+// in every d. Values received within d after the last put are discarded.
+func NewThrottle[T any](src ISource[T], d time.Duration) *Adapter[T] {
+	return newAdapter[T, T](
+		src,
+		func(out chan <- T){
+			var last time.Time
+			for v := range src.Out() {
+				now := time.Now()
+				if !last.IsZero() && (now.Sub(last) < d) {
+					continue
+				}
+				out <- v
+				last = now
+			}
+		},
+		func(out chan <- T){},
+	)
+}
+
+// Out returns output channel.
+func (a *Adapter[V]) Out() <- chan V {
+	return a.out
+}
+
+// Done returns done channel, which will be closed
+// when output channel of source is closed.
+func (a *Adapter[V]) Done() <- chan struct{} {
+	return a.done
+}
+
+// Error returns error of source after Adapter[V] stops.
+// Before Adapter[V] stops, nil is returned.
+func (a *Adapter[V]) Error() error {
+	select {
+	case <- a.done:
+		return a.err()
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,155 @@
+package qchan
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBatch(t *testing.T){
+	q := New[int]()
+	a := NewBatch[int](q, 3, 0)
+
+	for i := 0; i < 7; i++ {
+		q.In() <- i
+	}
+	close(q.In())
+
+	want := [][]int{{0, 1, 2}, {3, 4, 5}, {6}}
+	for _, w := range want {
+		b, ok := <- a.Out()
+		if !ok || (len(b) != len(w)) {
+			t.Errorf("Fail: %v, %v (want %v)\n", ok, b, w)
+			return
+		}
+		for i := range b {
+			if b[i] != w[i] {
+				t.Errorf("Fail: %v (want %v)\n", b, w)
+				return
+			}
+		}
+	}
+
+	if _, ok := <- a.Out(); ok {
+		t.Errorf("Must be closed\n")
+		return
+	}
+
+	<- a.Done()
+	if !errors.Is(a.Error(), ErrInputClosed) {
+		t.Errorf("Must be ErrInputClosed: %v\n", a.Error())
+		return
+	}
+}
+
+func TestBatchLinger(t *testing.T){
+	q := New[int]()
+	defer close(q.In())
+	a := NewBatch[int](q, 10, 10 * time.Millisecond)
+
+	q.In() <- 0
+	q.In() <- 1
+
+	select {
+	case b := <- a.Out():
+		if len(b) != 2 {
+			t.Errorf("Fail: %v\n", b)
+			return
+		}
+	case <- time.After(time.Second):
+		t.Errorf("Must be put after linger\n")
+		return
+	}
+
+	if a.Error() != nil {
+		t.Errorf("Must be nil before stop: %v\n", a.Error())
+		return
+	}
+}
+
+func TestWindow(t *testing.T){
+	q := New[int]()
+	a := NewWindow[int](q, 10 * time.Millisecond)
+
+	q.In() <- 0
+	q.In() <- 1
+
+	select {
+	case b := <- a.Out():
+		if len(b) != 2 {
+			t.Errorf("Fail: %v\n", b)
+			return
+		}
+	case <- time.After(time.Second):
+		t.Errorf("Must be put after window\n")
+		return
+	}
+
+	q.In() <- 2
+	close(q.In())
+
+	// Remained values are flushed.
+	b, ok := <- a.Out()
+	if !ok || (len(b) != 1) || (b[0] != 2) {
+		t.Errorf("Fail: %v, %v\n", ok, b)
+		return
+	}
+	if _, ok := <- a.Out(); ok {
+		t.Errorf("Must be closed\n")
+		return
+	}
+}
+
+func TestDebounce(t *testing.T){
+	q := New[int]()
+	a := NewDebounce[int](q, 20 * time.Millisecond)
+
+	for i := 0; i < 5; i++ {
+		q.In() <- i
+	}
+
+	select {
+	case v := <- a.Out():
+		if v != 4 {
+			t.Errorf("Fail: %d\n", v)
+			return
+		}
+	case <- time.After(time.Second):
+		t.Errorf("Must be put after quiet period\n")
+		return
+	}
+
+	q.In() <- 5
+	close(q.In())
+
+	if v, ok := <- a.Out(); !ok || (v != 5) {
+		t.Errorf("Fail: %v, %d\n", ok, v)
+		return
+	}
+	if _, ok := <- a.Out(); ok {
+		t.Errorf("Must be closed\n")
+		return
+	}
+}
+
+func TestThrottle(t *testing.T){
+	q := New[int]()
+	a := NewThrottle[int](q, time.Second)
+
+	go func(){
+		for i := 0; i < 5; i++ {
+			q.In() <- i
+		}
+		close(q.In())
+	}()
+
+	got := make([]int, 0)
+	for v := range a.Out() {
+		got = append(got, v)
+	}
+
+	if (len(got) != 1) || (got[0] != 0) {
+		t.Errorf("Fail: %v\n", got)
+		return
+	}
+}
@@ -0,0 +1,191 @@
+package qchan
+
+import (
+	"context"
+	"sync"
+)
+
+type (
+	// Broadcast[T] is a channel which delivers each input value
+	// to all of its subscribers.
+	// Each subscriber has its own infinite length queue,
+	// so that a slow subscriber doesn't block others.
+	Broadcast[T any] struct {
+		in chan <- T
+		ctx context.Context
+		done <- chan struct{}
+		mu sync.Mutex
+		subs map[*Subscription[T]]struct{}
+		closed bool
+	}
+
+	// Subscription[T] is a subscriber of Broadcast[T].
+	Subscription[T any] struct {
+		q *Queue[T]
+		filter func(T) bool
+	}
+)
+
+
+// NewBroadcast[T] creates a new Broadcast[T] and returns a pointer to it.
+func NewBroadcast[T any]() *Broadcast[T] {
+	return NewBroadcastWithContext[T](context.Background())
+}
+
+// NewBroadcastWithContext[T] creates a new Broadcast[T] and returns a pointer to it.
+// If ctx is cancelled, Broadcast[T] will not consumed input channel,
+// and the input channel will be blocked, however,
+// values which have already been delivered to subscribers
+// still will be put into their output channels.
+func NewBroadcastWithContext[T any](ctx context.Context) *Broadcast[T] {
+	in := make(chan T, 0)
+	done := make(chan struct{})
+	ctx, cause := context.WithCancelCause(ctx)
+
+	b := &Broadcast[T]{
+		in: in,
+		ctx: ctx,
+		done: done,
+		subs: map[*Subscription[T]]struct{}{},
+	}
+
+	go func(in <- chan T){
+		defer b.closeAll()
+		defer close(done)
+
+		for {
+			select {
+			case <- ctx.Done():
+				return
+			case v, ok := <- in:
+				if !ok {
+					cause(ErrInputClosed)
+					return
+				}
+
+				for _, s := range b.subscriptions() {
+					if (s.filter != nil) && !s.filter(v) {
+						continue
+					}
+
+					select {
+					case s.q.In() <- v:
+					case <- s.q.Done():
+						// Unsubscribed during delivery
+					}
+				}
+			}
+		}
+	}(in)
+
+	return b
+}
+
+// subscriptions returns snapshot of current subscriptions.
+func (b *Broadcast[T]) subscriptions() []*Subscription[T] {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subs := make([]*Subscription[T], 0, len(b.subs))
+	for s := range b.subs {
+		subs = append(subs, s)
+	}
+	return subs
+}
+
+// closeAll closes input channels of all subscriptions.
+// Only broadcast goroutine can call it, because it is the only sender.
+func (b *Broadcast[T]) closeAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for s := range b.subs {
+		close(s.q.In())
+	}
+	b.subs = map[*Subscription[T]]struct{}{}
+}
+
+// remove removes s from subscriptions.
+func (b *Broadcast[T]) remove(s *Subscription[T]) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.subs, s)
+}
+
+// In returns input channel.
+func (b *Broadcast[T]) In() chan <- T {
+	return b.in
+}
+
+// Done returns done channel, which will be closed
+// when Broadcast[T] stops consuming its input channel.
+func (b *Broadcast[T]) Done() <- chan struct{} {
+	return b.done
+}
+
+// Error returns error explaining cancel reason.
+func (b *Broadcast[T]) Error() error {
+	return context.Cause(b.ctx)
+}
+
+// Subscribe creates a new Subscription[T], which receives all values
+// put into input channel after subscription.
+// If ctx is cancelled, it is unsubscribed, however,
+// remained values still will be put into its output channel.
+// If Broadcast[T] has already stopped, output channel is closed immediately.
+func (b *Broadcast[T]) Subscribe(ctx context.Context) *Subscription[T] {
+	return b.SubscribeFunc(ctx, nil)
+}
+
+// SubscribeFunc creates a new Subscription[T], which receives values
+// for which filter returns true, e.g. values of specific topic.
+// filter is called at broadcast goroutine, so that it should be fast.
+// If ctx is cancelled, it is unsubscribed, however,
+// remained values still will be put into its output channel.
+// If Broadcast[T] has already stopped, output channel is closed immediately.
+func (b *Broadcast[T]) SubscribeFunc(ctx context.Context, filter func(T) bool) *Subscription[T] {
+	s := &Subscription[T]{
+		q: NewWithContext[T](ctx),
+		filter: filter,
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(s.q.In())
+		return s
+	}
+
+	b.subs[s] = struct{}{}
+	go func(){
+		<- s.q.Done()
+		b.remove(s)
+	}()
+
+	return s
+}
+
+// Out returns output channel.
+func (s *Subscription[T]) Out() <- chan T {
+	return s.q.Out()
+}
+
+// Done returns done channel, which will be closed
+// when Subscription[T] stops receiving values.
+func (s *Subscription[T]) Done() <- chan struct{} {
+	return s.q.Done()
+}
+
+// Error returns error explaining cancel reason.
+// If Broadcast[T] stops, ErrInputClosed is returned.
+func (s *Subscription[T]) Error() error {
+	return s.q.Error()
+}
+
+// Len returns the number of buffered values.
+func (s *Subscription[T]) Len() int {
+	return s.q.Len()
+}
@@ -0,0 +1,110 @@
+package qchan
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBroadcast(t *testing.T){
+	b := NewBroadcast[int]()
+
+	s1 := b.Subscribe(context.Background())
+	s2 := b.SubscribeFunc(context.Background(), func(v int) bool {
+		return v % 2 == 0
+	})
+
+	for i := 0; i < 5; i++ {
+		b.In() <- i
+	}
+	close(b.In())
+	<- b.Done()
+
+	if !errors.Is(b.Error(), ErrInputClosed) {
+		t.Errorf("Must be ErrInputClosed: %v\n", b.Error())
+		return
+	}
+
+	tests := []struct {
+		name string
+		s *Subscription[int]
+		want []int
+	}{
+		{ name: "All", s: s1, want: []int{0, 1, 2, 3, 4} },
+		{ name: "Filter", s: s2, want: []int{0, 2, 4} },
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T){
+			got := make([]int, 0)
+			for v := range test.s.Out() {
+				got = append(got, v)
+			}
+
+			if len(got) != len(test.want) {
+				t.Errorf("Fail: %v (want %v)\n", got, test.want)
+				return
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Errorf("Fail: %v (want %v)\n", got, test.want)
+					return
+				}
+			}
+		})
+	}
+
+	// Subscribe after stop
+	s3 := b.Subscribe(context.Background())
+	_, ok := <- s3.Out()
+	if ok {
+		t.Errorf("Must be closed\n")
+		return
+	}
+}
+
+func TestBroadcastUnsubscribe(t *testing.T){
+	b := NewBroadcast[int]()
+	defer close(b.In())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s1 := b.Subscribe(ctx)
+	s2 := b.Subscribe(context.Background())
+
+	// When 2nd value is received, 1st value has been delivered to all.
+	b.In() <- 0
+	b.In() <- 1
+	cancel()
+	<- s1.Done()
+
+	// Unsubscribed subscription doesn't block others.
+	for i := 2; i < 4; i++ {
+		select {
+		case b.In() <- i:
+		case <- time.After(time.Duration(100000000)):
+			t.Errorf("Must not block\n")
+			return
+		}
+	}
+
+	for i := 0; i < 4; i++ {
+		if v := <- s2.Out(); v != i {
+			t.Errorf("Fail: %d (want %d)\n", v, i)
+			return
+		}
+	}
+
+	// Remained value is still put.
+	if v, ok := <- s1.Out(); !ok || (v != 0) {
+		t.Errorf("Fail: %v, %d\n", ok, v)
+		return
+	}
+	for v := range s1.Out() {
+		if v > 1 {
+			t.Errorf("Must not receive after unsubscribe: %d\n", v)
+			return
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package qchan
+
+type (
+	// buffer[T] is interface for internal storage of Queue[T].
+	buffer[T any] interface {
+		// Len returns the number of stored values.
+		Len() int
+
+		// Push stores value.
+		Push(v T)
+
+		// Peek returns the next value without removing it.
+		// It must not be called when the buffer is empty.
+		Peek() T
+
+		// Pop removes and returns the next value.
+		// It must not be called when the buffer is empty.
+		Pop() T
+	}
+
+	// ringBuffer[T] is FIFO buffer backed by growable / shrinkable ring buffer.
+	ringBuffer[T any] struct {
+		s []T
+		head int
+		n int
+	}
+
+	// heapBuffer[T] is priority buffer backed by binary heap.
+	heapBuffer[T any] struct {
+		s []T
+		less func(a, b T) bool
+	}
+)
+
+
+const (
+	// minRingSize is the minimum size of ring buffer.
+	minRingSize = 16
+)
+
+func newRingBuffer[T any]() *ringBuffer[T] {
+	return &ringBuffer[T]{ s: make([]T, minRingSize) }
+}
+
+func (b *ringBuffer[T]) Len() int {
+	return b.n
+}
+
+func (b *ringBuffer[T]) Push(v T) {
+	if b.n == len(b.s) {
+		b.resize(2 * len(b.s))
+	}
+
+	b.s[(b.head + b.n) % len(b.s)] = v
+	b.n += 1
+}
+
+func (b *ringBuffer[T]) Peek() T {
+	return b.s[b.head]
+}
+
+func (b *ringBuffer[T]) Pop() T {
+	var zero T
+
+	v := b.s[b.head]
+	b.s[b.head] = zero // Release reference for GC
+	b.head = (b.head + 1) % len(b.s)
+	b.n -= 1
+
+	// Shrink with hysteresis, so that resize doesn't happen on every push / pop.
+	if (len(b.s) > minRingSize) && (b.n <= len(b.s) / 4) {
+		b.resize(len(b.s) / 2)
+	}
+
+	return v
+}
+
+// resize reallocates backing array with size and aligns values from its head.
+func (b *ringBuffer[T]) resize(size int) {
+	s := make([]T, size)
+	if b.head + b.n <= len(b.s) {
+		copy(s, b.s[b.head:b.head+b.n])
+	} else {
+		m := copy(s, b.s[b.head:])
+		copy(s[m:], b.s[:b.n-m])
+	}
+
+	b.s = s
+	b.head = 0
+}
+
+
+func (b *heapBuffer[T]) Len() int {
+	return len(b.s)
+}
+
+func (b *heapBuffer[T]) Push(v T) {
+	b.s = append(b.s, v)
+
+	// Sift up
+	i := len(b.s) - 1
+	for i > 0 {
+		p := (i - 1) / 2
+		if !b.less(b.s[i], b.s[p]) {
+			break
+		}
+		b.s[i], b.s[p] = b.s[p], b.s[i]
+		i = p
+	}
+}
+
+func (b *heapBuffer[T]) Peek() T {
+	return b.s[0]
+}
+
+func (b *heapBuffer[T]) Pop() T {
+	var zero T
+
+	v := b.s[0]
+	n := len(b.s) - 1
+	b.s[0] = b.s[n]
+	b.s[n] = zero // Release reference for GC
+	b.s = b.s[:n]
+
+	// Sift down
+	i := 0
+	for {
+		c := 2*i + 1
+		if c >= n {
+			break
+		}
+		if (c+1 < n) && b.less(b.s[c+1], b.s[c]) {
+			c += 1
+		}
+		if !b.less(b.s[c], b.s[i]) {
+			break
+		}
+		b.s[i], b.s[c] = b.s[c], b.s[i]
+		i = c
+	}
+
+	return v
+}
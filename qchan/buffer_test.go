@@ -0,0 +1,160 @@
+package qchan
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+type (
+	// sliceBuffer[T] is FIFO buffer backed by slice.
+	// The backing array is never reclaimed while the buffer stays non-empty,
+	// so that it is kept only for benchmark comparison.
+	sliceBuffer[T any] struct {
+		s []T
+	}
+)
+
+
+func (b *sliceBuffer[T]) Len() int {
+	return len(b.s)
+}
+
+func (b *sliceBuffer[T]) Push(v T) {
+	b.s = append(b.s, v)
+}
+
+func (b *sliceBuffer[T]) Peek() T {
+	return b.s[0]
+}
+
+func (b *sliceBuffer[T]) Pop() T {
+	v := b.s[0]
+	b.s = b.s[1:]
+	return v
+}
+
+
+func TestHeapBuffer(t *testing.T){
+	b := &heapBuffer[int]{ less: func(a, b int) bool { return a < b } }
+
+	values := make([]int, 0, 100)
+	for i := 0; i < 100; i++ {
+		v := rand.Intn(50)
+		values = append(values, v)
+		b.Push(v)
+	}
+	sort.Ints(values)
+
+	if b.Len() != len(values) {
+		t.Errorf("Wrong Len: %d\n", b.Len())
+		return
+	}
+
+	for i, w := range values {
+		if v := b.Peek(); v != w {
+			t.Errorf("Peek Fail at %d: %d (want %d)\n", i, v, w)
+			return
+		}
+		if v := b.Pop(); v != w {
+			t.Errorf("Pop Fail at %d: %d (want %d)\n", i, v, w)
+			return
+		}
+	}
+
+	if b.Len() != 0 {
+		t.Errorf("Must be empty: %d\n", b.Len())
+		return
+	}
+}
+
+func TestRingBuffer(t *testing.T){
+	b := newRingBuffer[int]()
+
+	// Push / Pop alternately with growing and shrinking
+	next := 0
+	want := 0
+	for _, n := range []int{5, 10, 100, 3, 1000, 10} {
+		for i := 0; i < n; i++ {
+			b.Push(next)
+			next += 1
+		}
+		for b.Len() > n / 2 {
+			if v := b.Peek(); v != want {
+				t.Errorf("Peek Fail: %d (want %d)\n", v, want)
+				return
+			}
+			if v := b.Pop(); v != want {
+				t.Errorf("Pop Fail: %d (want %d)\n", v, want)
+				return
+			}
+			want += 1
+		}
+	}
+
+	for b.Len() > 0 {
+		if v := b.Pop(); v != want {
+			t.Errorf("Pop Fail: %d (want %d)\n", v, want)
+			return
+		}
+		want += 1
+	}
+
+	if want != next {
+		t.Errorf("Lost values: %d (want %d)\n", want, next)
+		return
+	}
+
+	if len(b.s) != minRingSize {
+		t.Errorf("Must shrink: %d\n", len(b.s))
+		return
+	}
+}
+
+func benchmarkBuffer(b *testing.B, buf buffer[int]){
+	// Keep buffer non-empty with steady traffic.
+	for i := 0; i < 100; i++ {
+		buf.Push(i)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Push(i)
+		buf.Pop()
+	}
+}
+
+func BenchmarkSliceBuffer(b *testing.B){
+	benchmarkBuffer(b, &sliceBuffer[int]{ s: make([]int, 0) })
+}
+
+func BenchmarkRingBuffer(b *testing.B){
+	benchmarkBuffer(b, newRingBuffer[int]())
+}
+
+func benchmarkQueue(b *testing.B, buf buffer[int]){
+	q := newQueue[int](context.Background(), buf, 0, Block)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	go func(){
+		for i := 0; i < b.N; i++ {
+			q.In() <- i
+		}
+		close(q.In())
+	}()
+
+	for range q.Out() {
+	}
+}
+
+func BenchmarkSliceQueue(b *testing.B){
+	benchmarkQueue(b, &sliceBuffer[int]{ s: make([]int, 0) })
+}
+
+func BenchmarkRingQueue(b *testing.B){
+	benchmarkQueue(b, newRingBuffer[int]())
+}
@@ -0,0 +1,289 @@
+package qchan
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+type (
+	// ITimer is interface for timer created by IClock.
+	ITimer interface {
+		C() <- chan time.Time
+		Stop() bool
+		Reset(d time.Duration) bool
+	}
+
+	// IClock is interface for clock of Delay[T].
+	// It can be replaced for test.
+	IClock interface {
+		Now() time.Time
+		NewTimer(d time.Duration) ITimer
+	}
+
+	defaultClock struct {}
+
+	defaultTimer struct {
+		t *time.Timer
+	}
+
+	// Handle identifies a scheduled value of Delay[T].
+	Handle uint64
+
+	// Delay[T] is a queue based infinite length channel,
+	// which puts each value into output channel at its scheduled time.
+	Delay[T any] struct {
+		out <- chan T
+		ctx context.Context
+		cause context.CancelCauseFunc
+		done <- chan struct{}
+		clock IClock
+		wake chan struct{}
+		mu sync.Mutex
+		items delayHeap[T]
+		byHandle map[Handle]*delayItem[T]
+		next Handle
+	}
+
+	delayItem[T any] struct {
+		v T
+		at time.Time
+		handle Handle
+		index int
+	}
+
+	// delayHeap[T] implements heap.Interface ordered by scheduled time.
+	// Values scheduled at the same time are ordered by Handle (FIFO).
+	delayHeap[T any] []*delayItem[T]
+)
+
+
+func (_ defaultClock) Now() time.Time {
+	return time.Now()
+}
+
+func (_ defaultClock) NewTimer(d time.Duration) ITimer {
+	return &defaultTimer{ t: time.NewTimer(d) }
+}
+
+func (t *defaultTimer) C() <- chan time.Time {
+	return t.t.C
+}
+
+func (t *defaultTimer) Stop() bool {
+	return t.t.Stop()
+}
+
+func (t *defaultTimer) Reset(d time.Duration) bool {
+	return t.t.Reset(d)
+}
+
+
+func (h delayHeap[T]) Len() int {
+	return len(h)
+}
+
+func (h delayHeap[T]) Less(i, j int) bool {
+	if h[i].at.Equal(h[j].at) {
+		return h[i].handle < h[j].handle
+	}
+	return h[i].at.Before(h[j].at)
+}
+
+func (h delayHeap[T]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *delayHeap[T]) Push(x any) {
+	item := x.(*delayItem[T])
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *delayHeap[T]) Pop() any {
+	old := *h
+	n := len(old) - 1
+	item := old[n]
+	old[n] = nil // Release reference for GC
+	*h = old[:n]
+	return item
+}
+
+
+// NewDelay[T] creates a new Delay[T] and returns a pointer to it.
+func NewDelay[T any]() *Delay[T] {
+	return NewDelayWithContext[T](context.Background())
+}
+
+// NewDelayWithContext[T] creates a new Delay[T] and returns a pointer to it.
+// If ctx is cancelled, Delay[T] stops and scheduled values are discarded.
+func NewDelayWithContext[T any](ctx context.Context) *Delay[T] {
+	return NewDelayWithClock[T](ctx, defaultClock{})
+}
+
+// NewDelayWithClock[T] creates a new Delay[T] with clock
+// and returns a pointer to it.
+// If ctx is cancelled, Delay[T] stops and scheduled values are discarded.
+func NewDelayWithClock[T any](ctx context.Context, clock IClock) *Delay[T] {
+	out := make(chan T, 0)
+	done := make(chan struct{})
+	ctx, cause := context.WithCancelCause(ctx)
+
+	d := &Delay[T]{
+		out: out,
+		ctx: ctx,
+		cause: cause,
+		done: done,
+		clock: clock,
+		wake: make(chan struct{}, 1),
+		items: make(delayHeap[T], 0),
+		byHandle: map[Handle]*delayItem[T]{},
+	}
+
+	go func(out chan <- T){
+		defer close(out)
+		defer close(done)
+
+		timer := clock.NewTimer(time.Hour)
+		timer.Stop()
+		defer timer.Stop()
+
+		for {
+			v, wait, ready := d.pop()
+			if ready {
+				select {
+				case out <- v:
+				case <- ctx.Done():
+					return
+				}
+				continue
+			}
+
+			// When nothing is scheduled, only wake or ctx can resume.
+			var fire <- chan time.Time
+			if wait > 0 {
+				timer.Stop()
+				timer.Reset(wait)
+				fire = timer.C()
+			}
+
+			select {
+			case <- fire:
+			case <- d.wake:
+			case <- ctx.Done():
+				return
+			}
+		}
+	}(out)
+
+	return d
+}
+
+// pop removes and returns the first value if it is due.
+// Otherwise, duration until the first scheduled time is returned.
+// If nothing is scheduled, zero duration is returned.
+func (d *Delay[T]) pop() (T, time.Duration, bool) {
+	var zero T
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.items) == 0 {
+		return zero, 0, false
+	}
+
+	wait := d.items[0].at.Sub(d.clock.Now())
+	if wait > 0 {
+		return zero, wait, false
+	}
+
+	item := heap.Pop(&d.items).(*delayItem[T])
+	delete(d.byHandle, item.handle)
+	return item.v, 0, true
+}
+
+// notify wakes up Delay[T] goroutine without blocking.
+func (d *Delay[T]) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Put schedules v to be put into output channel at the time at,
+// and returns Handle to cancel it.
+// If at has already passed, v is put as soon as possible.
+// If Delay[T] has already stopped, context.Cause error is returned.
+func (d *Delay[T]) Put(v T, at time.Time) (Handle, error) {
+	select {
+	case <- d.ctx.Done():
+		return 0, context.Cause(d.ctx)
+	default:
+	}
+
+	d.mu.Lock()
+	d.next += 1
+	item := &delayItem[T]{ v: v, at: at, handle: d.next }
+	heap.Push(&d.items, item)
+	d.byHandle[item.handle] = item
+	d.mu.Unlock()
+
+	d.notify()
+	return item.handle, nil
+}
+
+// PutAfter schedules v to be put into output channel after delay,
+// and returns Handle to cancel it.
+func (d *Delay[T]) PutAfter(v T, delay time.Duration) (Handle, error) {
+	return d.Put(v, d.clock.Now().Add(delay))
+}
+
+// Cancel cancels scheduled value of h.
+// If the value has already been put or cancelled, false is returned.
+func (d *Delay[T]) Cancel(h Handle) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	item, ok := d.byHandle[h]
+	if !ok {
+		return false
+	}
+
+	heap.Remove(&d.items, item.index)
+	delete(d.byHandle, h)
+	return true
+}
+
+// Out returns output channel.
+func (d *Delay[T]) Out() <- chan T {
+	return d.out
+}
+
+// Done returns done channel, which will be closed when Delay[T] stops.
+func (d *Delay[T]) Done() <- chan struct{} {
+	return d.done
+}
+
+// Error returns error explaining cancel reason.
+func (d *Delay[T]) Error() error {
+	return context.Cause(d.ctx)
+}
+
+// Len returns the number of scheduled values.
+func (d *Delay[T]) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return len(d.items)
+}
+
+// Close stops Delay[T] and discards scheduled values.
+// Error() returns ErrClosed unless Delay[T] has already stopped.
+func (d *Delay[T]) Close() error {
+	d.cause(ErrClosed)
+	<- d.done
+	return nil
+}
@@ -0,0 +1,197 @@
+package qchan
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type (
+	fakeClock struct {
+		mu sync.Mutex
+		now time.Time
+		timers []*fakeTimer
+	}
+
+	fakeTimer struct {
+		clock *fakeClock
+		c chan time.Time
+		at time.Time
+		active bool
+	}
+)
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{ now: time.Unix(0, 0) }
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) ITimer {
+	t := &fakeTimer{ clock: c, c: make(chan time.Time, 1) }
+
+	c.mu.Lock()
+	c.timers = append(c.timers, t)
+	c.mu.Unlock()
+
+	t.Reset(d)
+	return t
+}
+
+// Advance forwards clock and fires expired timers.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	for _, t := range c.timers {
+		t.fire()
+	}
+}
+
+// fire must be called with lock.
+func (t *fakeTimer) fire() {
+	if t.active && !t.at.After(t.clock.now) {
+		t.active = false
+		select {
+		case t.c <- t.clock.now:
+		default:
+		}
+	}
+}
+
+func (t *fakeTimer) C() <- chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	active := t.active
+	t.active = false
+	return active
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	active := t.active
+	t.active = true
+	t.at = t.clock.now.Add(d)
+	t.fire()
+	return active
+}
+
+
+func TestDelay(t *testing.T){
+	clock := newFakeClock()
+	d := NewDelayWithClock[string](context.Background(), clock)
+	defer d.Close()
+
+	start := clock.Now()
+	if _, err := d.Put("c", start.Add(10 * time.Second)); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if _, err := d.PutAfter("a", 5 * time.Second); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	h, err := d.PutAfter("b", 7 * time.Second)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	if d.Len() != 3 {
+		t.Errorf("Wrong Len: %d\n", d.Len())
+		return
+	}
+
+	if !d.Cancel(h) {
+		t.Errorf("Cancel must succeed\n")
+		return
+	}
+	if d.Cancel(h) {
+		t.Errorf("Cancel must fail for cancelled value\n")
+		return
+	}
+
+	want := []struct {
+		v string
+		at time.Duration
+	}{
+		{ v: "a", at: 5 * time.Second },
+		{ v: "c", at: 10 * time.Second },
+	}
+
+	for _, w := range want {
+		var v string
+		RECV:
+		for {
+			select {
+			case v = <- d.Out():
+				break RECV
+			case <- time.After(time.Millisecond):
+				clock.Advance(time.Second)
+			}
+		}
+
+		if v != w.v {
+			t.Errorf("Fail: %s (want %s)\n", v, w.v)
+			return
+		}
+		if clock.Now().Sub(start) < w.at {
+			t.Errorf("Put too early: %s at %v\n", v, clock.Now().Sub(start))
+			return
+		}
+	}
+
+	if d.Len() != 0 {
+		t.Errorf("Wrong Len: %d\n", d.Len())
+		return
+	}
+}
+
+func TestDelayClose(t *testing.T){
+	d := NewDelay[int]()
+
+	if _, err := d.PutAfter(0, time.Hour); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	// Past time is put immediately.
+	if _, err := d.Put(1, time.Now().Add(-time.Second)); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if v := <- d.Out(); v != 1 {
+		t.Errorf("Fail: %d\n", v)
+		return
+	}
+
+	d.Close()
+	if !errors.Is(d.Error(), ErrClosed) {
+		t.Errorf("Must be ErrClosed: %v\n", d.Error())
+		return
+	}
+
+	if _, ok := <- d.Out(); ok {
+		t.Errorf("Must be closed\n")
+		return
+	}
+
+	if _, err := d.PutAfter(2, 0); !errors.Is(err, ErrClosed) {
+		t.Errorf("Must be ErrClosed: %v\n", err)
+		return
+	}
+}
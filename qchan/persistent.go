@@ -0,0 +1,197 @@
+package qchan
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+type (
+	// IEncoder is interface for value encoder of Persistent[T].
+	// Encoder in github.com/ymd-h/go/encoding can be used.
+	IEncoder interface {
+		Encode(any) (io.Reader, error)
+	}
+
+	// IDecoder is interface for value decoder of Persistent[T].
+	// Decoder in github.com/ymd-h/go/encoding can be used.
+	IDecoder interface {
+		Decode(io.Reader, any) error
+	}
+
+	// Entry[T] is a value with its sequence number, which is used for Ack.
+	Entry[T any] struct {
+		Seq uint64
+		Value T
+	}
+
+	// Persistent[T] is a queue based infinite length channel,
+	// which persists values to append-only segment files in a directory.
+	// Values which are not acknowledged are put into output channel again
+	// when Persistent[T] is created for the same directory.
+	Persistent[T any] struct {
+		in chan <- T
+		q *Queue[Entry[T]]
+		log *segmentLog
+		cause context.CancelCauseFunc
+		stopped <- chan struct{}
+	}
+)
+
+var (
+	ErrNotPending = errors.New("Value is not pending.")
+)
+
+
+// NewPersistent[T] creates a new Persistent[T] at dir and returns a pointer to it.
+// Unacknowledged values stored in dir are put into output channel first.
+func NewPersistent[T any](dir string, enc IEncoder, dec IDecoder) (*Persistent[T], error) {
+	return NewPersistentWithContext[T](context.Background(), dir, enc, dec)
+}
+
+// NewPersistentWithContext[T] creates a new Persistent[T] at dir
+// and returns a pointer to it.
+// Unacknowledged values stored in dir are put into output channel first.
+// If ctx is cancelled, Persistent[T] will not consumed input channel,
+// and the input channel will be blocked, however,
+// remained values still will be put into output channel.
+func NewPersistentWithContext[T any](ctx context.Context, dir string, enc IEncoder, dec IDecoder) (*Persistent[T], error) {
+	log, records, err := openSegmentLog(dir)
+	if err != nil {
+		return nil, fmt.Errorf("Fail to Open Persistent Queue: %w", err)
+	}
+
+	replay := make([]Entry[T], 0, len(records))
+	for _, r := range records {
+		var v T
+		if err := dec.Decode(bytes.NewReader(r.payload), &v); err != nil {
+			log.close()
+			return nil, fmt.Errorf("Fail to Decode Seq %d: %w", r.seq, err)
+		}
+		replay = append(replay, Entry[T]{ Seq: r.seq, Value: v })
+	}
+
+	in := make(chan T, 0)
+	stopped := make(chan struct{})
+	ctx, cause := context.WithCancelCause(ctx)
+	q := NewWithContext[Entry[T]](ctx)
+
+	go func(in <- chan T){
+		defer close(stopped)
+
+		send := func(e Entry[T]) bool {
+			select {
+			case q.In() <- e:
+				return true
+			case <- q.Done():
+				return false
+			}
+		}
+
+		for _, e := range replay {
+			if !send(e) {
+				return
+			}
+		}
+
+		for {
+			select {
+			case <- ctx.Done():
+				return
+			case v, ok := <- in:
+				if !ok {
+					close(q.In())
+					return
+				}
+
+				seq, err := persist(log, enc, v)
+				if err != nil {
+					cause(err)
+					return
+				}
+
+				if !send(Entry[T]{ Seq: seq, Value: v }) {
+					return
+				}
+			}
+		}
+	}(in)
+
+	return &Persistent[T]{
+		in: in,
+		q: q,
+		log: log,
+		cause: cause,
+		stopped: stopped,
+	}, nil
+}
+
+// persist encodes v and writes it to log.
+func persist[T any](log *segmentLog, enc IEncoder, v T) (uint64, error) {
+	r, err := enc.Encode(v)
+	if err != nil {
+		return 0, fmt.Errorf("Fail to Encode: %w", err)
+	}
+
+	var payload []byte
+	if r != nil {
+		payload, err = io.ReadAll(r)
+		if err != nil {
+			return 0, fmt.Errorf("Fail to Encode: %w", err)
+		}
+	}
+
+	return log.put(payload)
+}
+
+// In returns input channel.
+func (p *Persistent[T]) In() chan <- T {
+	return p.in
+}
+
+// Out returns output channel.
+// Each value must be acknowledged with Ack after it is processed,
+// otherwise it is put into output channel again at next time.
+func (p *Persistent[T]) Out() <- chan Entry[T] {
+	return p.q.Out()
+}
+
+// Ack acknowledges the value of seq, and it will never be replayed.
+// If the value is not pending, ErrNotPending is returned.
+func (p *Persistent[T]) Ack(seq uint64) error {
+	return p.log.ack(seq)
+}
+
+// Done returns done channel, which will be closed
+// when Persistent[T] stops consuming its input channel.
+func (p *Persistent[T]) Done() <- chan struct{} {
+	return p.q.Done()
+}
+
+// Error returns error explaining cancel reason.
+func (p *Persistent[T]) Error() error {
+	return p.q.Error()
+}
+
+// Len returns the number of buffered values.
+// Values which have been put into output channel but not acknowledged
+// are not included.
+func (p *Persistent[T]) Len() int {
+	return p.q.Len()
+}
+
+// Close stops consuming input channel and closes segment file.
+// Remained values still will be put into output channel,
+// however, they cannot be acknowledged anymore and
+// will be put into output channel again at next time.
+func (p *Persistent[T]) Close() error {
+	p.cause(ErrClosed)
+	<- p.stopped
+
+	// Don't wait flush, since remained values are replayed at next time.
+	p.q.cause(ErrClosed)
+	<- p.q.done
+	return p.log.close()
+}
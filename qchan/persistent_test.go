@@ -0,0 +1,275 @@
+package qchan
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ymd-h/go/encoding/json"
+)
+
+func TestPersistent(t *testing.T){
+	dir := t.TempDir()
+
+	p, err := NewPersistent[string](dir, json.Encoder{}, json.Decoder{})
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	values := []string{"a", "b", "c"}
+	for _, v := range values {
+		p.In() <- v
+	}
+
+	entries := make([]Entry[string], 0, len(values))
+	for i := range values {
+		e := <- p.Out()
+		if e.Value != values[i] {
+			t.Errorf("Fail: %s (want %s)\n", e.Value, values[i])
+			return
+		}
+		entries = append(entries, e)
+	}
+
+	// Ack only first one.
+	if err := p.Ack(entries[0].Seq); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if err := p.Ack(entries[0].Seq); !errors.Is(err, ErrNotPending) {
+		t.Errorf("Must be ErrNotPending: %v\n", err)
+		return
+	}
+
+	if err := p.Close(); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if !errors.Is(p.Error(), ErrClosed) {
+		t.Errorf("Must be ErrClosed: %v\n", p.Error())
+		return
+	}
+
+	// Unacknowledged values are replayed.
+	p, err = NewPersistent[string](dir, json.Encoder{}, json.Decoder{})
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	for _, want := range entries[1:] {
+		e := <- p.Out()
+		if e != want {
+			t.Errorf("Fail: %+v (want %+v)\n", e, want)
+			return
+		}
+		if err := p.Ack(e.Seq); err != nil {
+			t.Errorf("Fail: %v\n", err)
+			return
+		}
+	}
+
+	// New value has new sequence number.
+	p.In() <- "d"
+	e := <- p.Out()
+	if (e.Value != "d") || (e.Seq <= entries[2].Seq) {
+		t.Errorf("Fail: %+v\n", e)
+		return
+	}
+	if err := p.Ack(e.Seq); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	close(p.In())
+	<- p.Done()
+	if !errors.Is(p.Error(), ErrInputClosed) {
+		t.Errorf("Must be ErrInputClosed: %v\n", p.Error())
+		return
+	}
+	p.Close()
+
+	// All values have been acknowledged.
+	p, err = NewPersistent[string](dir, json.Encoder{}, json.Decoder{})
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	defer p.Close()
+
+	select {
+	case e := <- p.Out():
+		t.Errorf("Must be empty: %+v\n", e)
+		return
+	case <- time.After(time.Duration(1000000)):
+	}
+}
+
+func TestPersistentCompaction(t *testing.T){
+	size := maxSegmentSize
+	maxSegmentSize = 64
+	defer func(){ maxSegmentSize = size }()
+
+	dir := t.TempDir()
+
+	p, err := NewPersistent[int](dir, json.Encoder{}, json.Decoder{})
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	defer p.Close()
+
+	for i := 0; i < 20; i++ {
+		p.In() <- i
+		e := <- p.Out()
+		if err := p.Ack(e.Seq); err != nil {
+			t.Errorf("Fail: %v\n", err)
+			return
+		}
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*" + segmentExt))
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if len(paths) > 2 {
+		t.Errorf("Segments must be removed: %v\n", paths)
+		return
+	}
+}
+
+func TestPersistentSeqAfterCompaction(t *testing.T){
+	size := maxSegmentSize
+	maxSegmentSize = 64
+	defer func(){ maxSegmentSize = size }()
+
+	dir := t.TempDir()
+
+	var last uint64
+	for restart := 0; restart < 3; restart++ {
+		p, err := NewPersistent[int](dir, json.Encoder{}, json.Decoder{})
+		if err != nil {
+			t.Errorf("Fail: %v\n", err)
+			return
+		}
+
+		for i := 0; i < 5; i++ {
+			p.In() <- i
+			e := <- p.Out()
+			if e.Seq <= last {
+				t.Errorf("Seq must increase: %d (last %d)\n", e.Seq, last)
+				p.Close()
+				return
+			}
+			last = e.Seq
+
+			if err := p.Ack(e.Seq); err != nil {
+				t.Errorf("Fail: %v\n", err)
+				p.Close()
+				return
+			}
+		}
+
+		if err := p.Close(); err != nil {
+			t.Errorf("Fail: %v\n", err)
+			return
+		}
+	}
+}
+
+// faultyFile writes only a half of data and fails.
+type faultyFile struct {
+	segmentFile
+	truncate error
+}
+
+func (f *faultyFile) Write(p []byte) (int, error) {
+	n, _ := f.segmentFile.Write(p[:len(p)/2])
+	return n, errors.New("Faulty write")
+}
+
+func (f *faultyFile) Truncate(size int64) error {
+	if f.truncate != nil {
+		return f.truncate
+	}
+	return f.segmentFile.Truncate(size)
+}
+
+func TestSegmentWriteFailure(t *testing.T){
+	dir := t.TempDir()
+
+	l, _, err := openSegmentLog(dir)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	s1, err := l.put([]byte("a"))
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	s2, err := l.put([]byte("b"))
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	cur := l.cur
+	l.cur = &faultyFile{ segmentFile: cur }
+	if err := l.ack(s1); err == nil {
+		t.Errorf("Must Fail\n")
+		return
+	}
+	l.cur = cur
+
+	// Partial record is truncated, so that following writes are valid.
+	if err := l.ack(s1); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	s3, err := l.put([]byte("c"))
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	l.close()
+
+	l, records, err := openSegmentLog(dir)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if (len(records) != 2) || (records[0].seq != s2) || (records[1].seq != s3) {
+		t.Errorf("Fail: %v\n", records)
+		return
+	}
+
+	// Log which cannot be truncated rejects further writes.
+	cur = l.cur
+	l.cur = &faultyFile{ segmentFile: cur, truncate: errors.New("Faulty truncate") }
+	if err := l.ack(s2); err == nil {
+		t.Errorf("Must Fail\n")
+		return
+	}
+	l.cur = cur
+	if _, err := l.put([]byte("d")); !errors.Is(err, errSegmentFailed) {
+		t.Errorf("Must be errSegmentFailed: %v\n", err)
+		return
+	}
+	l.close()
+
+	l, records, err = openSegmentLog(dir)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	defer l.close()
+	if (len(records) != 2) || (string(records[0].payload) != "b") {
+		t.Errorf("Fail: %v\n", records)
+		return
+	}
+}
@@ -0,0 +1,328 @@
+// Package pipeline provides typed stage helpers to build channel pipelines.
+//
+// All stages belong to a Pipeline, which shares context among them.
+// When a stage fails, the Pipeline is cancelled with the error,
+// and all the stages stop and close their output channels.
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+type (
+	// Pipeline manages lifecycle of stages.
+	Pipeline struct {
+		ctx context.Context
+		cause context.CancelCauseFunc
+		wg sync.WaitGroup
+	}
+)
+
+var (
+	ErrUnknownKey = errors.New("Unknown key")
+)
+
+
+// New creates a new Pipeline and returns a pointer to it.
+// If ctx is cancelled, all the stages stop.
+func New(ctx context.Context) *Pipeline {
+	ctx, cause := context.WithCancelCause(ctx)
+	return &Pipeline{ ctx: ctx, cause: cause }
+}
+
+// Context returns context shared among stages.
+func (p *Pipeline) Context() context.Context {
+	return p.ctx
+}
+
+// Done returns done channel, which will be closed
+// when the Pipeline is cancelled.
+func (p *Pipeline) Done() <- chan struct{} {
+	return p.ctx.Done()
+}
+
+// Wait waits all the stages finish and returns the first stage error.
+// If the parent context is cancelled, context.Cause(ctx) is returned.
+// Wait must be called to release resources after the pipeline is built.
+func (p *Pipeline) Wait() error {
+	p.wg.Wait()
+
+	err := context.Cause(p.ctx)
+	p.cause(nil)
+	return err
+}
+
+// fail cancels the Pipeline with err.
+func (p *Pipeline) fail(err error) {
+	p.cause(err)
+}
+
+// goStage runs f as a stage in a new goroutine.
+func (p *Pipeline) goStage(f func()) {
+	p.wg.Add(1)
+	go func(){
+		defer p.wg.Done()
+		f()
+	}()
+}
+
+// send puts v into out.
+// If the Pipeline is cancelled, false is returned.
+func send[T any](ctx context.Context, out chan <- T, v T) bool {
+	select {
+	case out <- v:
+		return true
+	case <- ctx.Done():
+		return false
+	}
+}
+
+// recv gets a value from in.
+// If in is closed or the Pipeline is cancelled, false is returned.
+func recv[T any](ctx context.Context, in <- chan T) (T, bool) {
+	select {
+	case v, ok := <- in:
+		return v, ok
+	case <- ctx.Done():
+		var zero T
+		return zero, false
+	}
+}
+
+// Map creates a stage which puts f(v) for each v from in.
+func Map[T, U any](p *Pipeline, in <- chan T, f func(T) (U, error)) <- chan U {
+	out := make(chan U, 0)
+
+	p.goStage(func(){
+		defer close(out)
+
+		for {
+			v, ok := recv(p.ctx, in)
+			if !ok {
+				return
+			}
+
+			u, err := f(v)
+			if err != nil {
+				p.fail(fmt.Errorf("Fail at Map: %w", err))
+				return
+			}
+
+			if !send(p.ctx, out, u) {
+				return
+			}
+		}
+	})
+
+	return out
+}
+
+// Filter creates a stage which puts v from in only when f(v) is true.
+func Filter[T any](p *Pipeline, in <- chan T, f func(T) (bool, error)) <- chan T {
+	out := make(chan T, 0)
+
+	p.goStage(func(){
+		defer close(out)
+
+		for {
+			v, ok := recv(p.ctx, in)
+			if !ok {
+				return
+			}
+
+			keep, err := f(v)
+			if err != nil {
+				p.fail(fmt.Errorf("Fail at Filter: %w", err))
+				return
+			}
+
+			if keep && !send(p.ctx, out, v) {
+				return
+			}
+		}
+	})
+
+	return out
+}
+
+// Merge creates a stage which puts values from all ins (fan-in).
+// Output channel is closed after all ins are closed.
+func Merge[T any](p *Pipeline, ins ...<- chan T) <- chan T {
+	out := make(chan T, 0)
+
+	var wg sync.WaitGroup
+	for _, in := range ins {
+		wg.Add(1)
+		p.goStage(func(in <- chan T) func() {
+			return func(){
+				defer wg.Done()
+
+				for {
+					v, ok := recv(p.ctx, in)
+					if !ok {
+						return
+					}
+
+					if !send(p.ctx, out, v) {
+						return
+					}
+				}
+			}
+		}(in))
+	}
+
+	p.goStage(func(){
+		wg.Wait()
+		close(out)
+	})
+
+	return out
+}
+
+// Split creates a stage which puts v from in
+// into the output channel for key(v) (fan-out by key).
+// If key(v) is not in keys, the Pipeline fails with ErrUnknownKey.
+func Split[T any, K comparable](p *Pipeline, in <- chan T, keys []K, key func(T) K) map[K]<- chan T {
+	outs := make(map[K]chan T, len(keys))
+	ret := make(map[K]<- chan T, len(keys))
+	for _, k := range keys {
+		ch := make(chan T, 0)
+		outs[k] = ch
+		ret[k] = ch
+	}
+
+	p.goStage(func(){
+		defer func(){
+			for _, ch := range outs {
+				close(ch)
+			}
+		}()
+
+		for {
+			v, ok := recv(p.ctx, in)
+			if !ok {
+				return
+			}
+
+			k := key(v)
+			ch, ok := outs[k]
+			if !ok {
+				p.fail(fmt.Errorf("Fail at Split: %w: %v", ErrUnknownKey, k))
+				return
+			}
+
+			if !send(p.ctx, ch, v) {
+				return
+			}
+		}
+	})
+
+	return ret
+}
+
+// Tee creates a stage which puts every v from in into all n output channels.
+// Since v is put into output channels one by one,
+// a slow consumer blocks others.
+// If n is not positive, no output channels are returned
+// and values from in are discarded.
+func Tee[T any](p *Pipeline, in <- chan T, n int) []<- chan T {
+	if n < 0 {
+		n = 0
+	}
+	outs := make([]chan T, n)
+	ret := make([]<- chan T, n)
+	for i := range outs {
+		outs[i] = make(chan T, 0)
+		ret[i] = outs[i]
+	}
+
+	p.goStage(func(){
+		defer func(){
+			for _, ch := range outs {
+				close(ch)
+			}
+		}()
+
+		for {
+			v, ok := recv(p.ctx, in)
+			if !ok {
+				return
+			}
+
+			for _, ch := range outs {
+				if !send(p.ctx, ch, v) {
+					return
+				}
+			}
+		}
+	})
+
+	return ret
+}
+
+// MapN creates a stage which puts f(v) for each v from in,
+// where f is executed in parallel at most n at the same time.
+// The order of output is same as that of input.
+// If n is not positive, it is treated as 1.
+func MapN[T, U any](p *Pipeline, in <- chan T, n int, f func(T) (U, error)) <- chan U {
+	if n < 1 {
+		n = 1
+	}
+	out := make(chan U, 0)
+	order := make(chan chan U, n)
+	sem := make(chan struct{}, n)
+
+	// Dispatcher
+	p.goStage(func(){
+		defer close(order)
+
+		for {
+			v, ok := recv(p.ctx, in)
+			if !ok {
+				return
+			}
+
+			if !send(p.ctx, sem, struct{}{}) {
+				return
+			}
+
+			res := make(chan U, 1)
+			if !send(p.ctx, order, res) {
+				<- sem
+				return
+			}
+
+			p.goStage(func(){
+				defer func(){ <- sem }()
+
+				u, err := f(v)
+				if err != nil {
+					p.fail(fmt.Errorf("Fail at MapN: %w", err))
+					return
+				}
+				res <- u
+			})
+		}
+	})
+
+	// Collector
+	p.goStage(func(){
+		defer close(out)
+
+		for res := range order {
+			u, ok := recv(p.ctx, res)
+			if !ok {
+				return
+			}
+
+			if !send(p.ctx, out, u) {
+				return
+			}
+		}
+	})
+
+	return out
+}
@@ -0,0 +1,258 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+)
+
+// source creates a channel which puts values and is closed.
+func source[T any](values ...T) <- chan T {
+	ch := make(chan T, len(values))
+	for _, v := range values {
+		ch <- v
+	}
+	close(ch)
+	return ch
+}
+
+// collect gets all values from in.
+func collect[T any](in <- chan T) []T {
+	values := make([]T, 0)
+	for v := range in {
+		values = append(values, v)
+	}
+	return values
+}
+
+func equal[T comparable](a, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMapFilter(t *testing.T){
+	p := New(context.Background())
+
+	m := Map(p, source(1, 2, 3, 4, 5), func(v int) (int, error) {
+		return v * v, nil
+	})
+	f := Filter(p, m, func(v int) (bool, error) {
+		return v % 2 == 1, nil
+	})
+
+	got := collect(f)
+	if err := p.Wait(); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	if want := []int{1, 9, 25}; !equal(got, want) {
+		t.Errorf("Fail: %v (want %v)\n", got, want)
+		return
+	}
+}
+
+func TestMapError(t *testing.T){
+	p := New(context.Background())
+	errStage := errors.New("stage error")
+
+	m := Map(p, source(1, 2, 3), func(v int) (int, error) {
+		if v == 2 {
+			return 0, errStage
+		}
+		return v, nil
+	})
+
+	collect(m)
+	if err := p.Wait(); !errors.Is(err, errStage) {
+		t.Errorf("Must be stage error: %v\n", err)
+		return
+	}
+}
+
+func TestCancel(t *testing.T){
+	ctx, cancel := context.WithCancel(context.Background())
+	p := New(ctx)
+
+	// Never closed input
+	in := make(chan int)
+	m := Map(p, in, func(v int) (int, error) { return v, nil })
+
+	cancel()
+	collect(m)
+	if err := p.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Must be context.Canceled: %v\n", err)
+		return
+	}
+}
+
+func TestMerge(t *testing.T){
+	p := New(context.Background())
+
+	got := collect(Merge(p, source(1, 2), source(3), source(4, 5)))
+	if err := p.Wait(); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	sort.Ints(got)
+	if want := []int{1, 2, 3, 4, 5}; !equal(got, want) {
+		t.Errorf("Fail: %v (want %v)\n", got, want)
+		return
+	}
+}
+
+func TestSplit(t *testing.T){
+	p := New(context.Background())
+
+	outs := Split(p, source(1, 2, 3, 4, 5), []string{"odd", "even"}, func(v int) string {
+		if v % 2 == 0 {
+			return "even"
+		}
+		return "odd"
+	})
+
+	var odd, even []int
+	done := make(chan struct{})
+	go func(){
+		defer close(done)
+		odd = collect(outs["odd"])
+	}()
+	even = collect(outs["even"])
+	<- done
+
+	if err := p.Wait(); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	if want := []int{1, 3, 5}; !equal(odd, want) {
+		t.Errorf("Fail: %v (want %v)\n", odd, want)
+		return
+	}
+	if want := []int{2, 4}; !equal(even, want) {
+		t.Errorf("Fail: %v (want %v)\n", even, want)
+		return
+	}
+}
+
+func TestSplitUnknownKey(t *testing.T){
+	p := New(context.Background())
+
+	outs := Split(p, source(1, 2), []int{1}, func(v int) int { return v })
+	collect(outs[1])
+
+	if err := p.Wait(); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Must be ErrUnknownKey: %v\n", err)
+		return
+	}
+}
+
+func TestTee(t *testing.T){
+	p := New(context.Background())
+
+	outs := Tee(p, source(1, 2, 3), 2)
+
+	var a []int
+	done := make(chan struct{})
+	go func(){
+		defer close(done)
+		a = collect(outs[0])
+	}()
+	b := collect(outs[1])
+	<- done
+
+	if err := p.Wait(); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	want := []int{1, 2, 3}
+	if !equal(a, want) || !equal(b, want) {
+		t.Errorf("Fail: %v, %v (want %v)\n", a, b, want)
+		return
+	}
+}
+
+func TestMapN(t *testing.T){
+	p := New(context.Background())
+
+	values := make([]int, 20)
+	for i := range values {
+		values[i] = i
+	}
+
+	m := MapN(p, source(values...), 4, func(v int) (int, error) {
+		// Later values finish earlier.
+		time.Sleep(time.Duration(20 - v) * time.Millisecond)
+		return v * 2, nil
+	})
+
+	got := collect(m)
+	if err := p.Wait(); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	for i, v := range got {
+		if v != i * 2 {
+			t.Errorf("Order is not kept: %v\n", got)
+			return
+		}
+	}
+	if len(got) != len(values) {
+		t.Errorf("Wrong Length: %d\n", len(got))
+		return
+	}
+}
+
+func TestMapNError(t *testing.T){
+	p := New(context.Background())
+	errStage := errors.New("stage error")
+
+	m := MapN(p, source(1, 2, 3, 4), 2, func(v int) (int, error) {
+		if v == 3 {
+			return 0, errStage
+		}
+		return v, nil
+	})
+
+	collect(m)
+	if err := p.Wait(); !errors.Is(err, errStage) {
+		t.Errorf("Must be stage error: %v\n", err)
+		return
+	}
+}
+
+func TestNonPositiveN(t *testing.T){
+	for _, n := range []int{0, -1} {
+		p := New(context.Background())
+
+		m := MapN(p, source(1, 2, 3), n, func(v int) (int, error) {
+			return v * 2, nil
+		})
+		if got := collect(m); !equal(got, []int{2, 4, 6}) {
+			t.Errorf("Fail: MapN(%d): %v\n", n, got)
+			return
+		}
+
+		if outs := Tee(p, source(1, 2, 3), n); len(outs) != 0 {
+			t.Errorf("Fail: Tee(%d): %d\n", n, len(outs))
+			return
+		}
+
+		if err := p.Wait(); err != nil {
+			t.Errorf("Fail: %v\n", err)
+			return
+		}
+	}
+}
@@ -1,9 +1,10 @@
-// Package qchan provides queue based infinite length channel.
+// Package qchan provides queue based infinite (or bounded) length channel.
 package qchan
 
 import (
 	"context"
 	"errors"
+	"sync/atomic"
 )
 
 type (
@@ -12,15 +13,85 @@ type (
 		in chan <- T
 		out <- chan T
 		ctx context.Context
+		cause context.CancelCauseFunc
+		done <- chan struct{}
+		flushed <- chan struct{}
+		stats *stats
 	}
+
+	// Stats is a snapshot of Queue[T] statistics.
+	Stats struct {
+		// Len is the number of buffered values.
+		Len int
+
+		// HighWaterMark is the maximum number of buffered values so far.
+		HighWaterMark int
+
+		// Dropped is the number of values discarded by OverflowPolicy.
+		Dropped int
+	}
+
+	stats struct {
+		len atomic.Int64
+		hwm atomic.Int64
+		dropped atomic.Int64
+	}
+
+	// statBuffer[T] wraps buffer[T] and records statistics.
+	statBuffer[T any] struct {
+		buffer[T]
+		stats *stats
+	}
+
+	// OverflowPolicy specifies how to handle input value when Queue[T] is full.
+	OverflowPolicy int
+)
+
+const (
+	// Block stops consuming input channel until Queue[T] has space.
+	Block OverflowPolicy = iota
+
+	// DropNewest discards the input value.
+	DropNewest
+
+	// DropOldest discards the oldest value in Queue[T] to make space.
+	// For priority queue, the value which would be output next is discarded.
+	DropOldest
+
+	// Fail stops consuming input channel, and Error() returns ErrOverflow.
+	// The overflowing value is not discarded but kept beyond capacity,
+	// so that it is still put into output channel.
+	Fail
 )
 
 
 var (
 	ErrInputClosed = errors.New("Input channel has aleady been closed.")
+	ErrOverflow = errors.New("Queue is overflowed.")
+	ErrClosed = errors.New("Queue has been closed.")
+	ErrDrained = errors.New("Queue has been drained.")
+
+	// Deprecated: ErrUnknown is no longer returned.
+	// Each termination reason has its own error.
 	ErrUnknown = errors.New("Finish with unknown reason")
 )
 
+
+func (b *statBuffer[T]) Push(v T) {
+	b.buffer.Push(v)
+
+	n := b.stats.len.Add(1)
+	if n > b.stats.hwm.Load() {
+		// Only queue goroutine writes, so that we don't need CAS.
+		b.stats.hwm.Store(n)
+	}
+}
+
+func (b *statBuffer[T]) Pop() T {
+	b.stats.len.Add(-1)
+	return b.buffer.Pop()
+}
+
 // New[T] creates a new Queue[T] and returns a pointer to it.
 func New[T any]() *Queue[T] {
 	return NewWithContext[T](context.Background())
@@ -31,60 +102,123 @@ func New[T any]() *Queue[T] {
 // and the input channel will be blocked, however,
 // remained values still will be put into output channel.
 func NewWithContext[T any](ctx context.Context) *Queue[T] {
+	return NewBoundedWithContext[T](ctx, 0, Block)
+}
+
+// NewBounded[T] creates a new Queue[T] with maximum length and returns a pointer to it.
+// When the queue is full, the input value is handled according to policy.
+// If capacity is not positive, the queue is unbounded.
+func NewBounded[T any](capacity int, policy OverflowPolicy) *Queue[T] {
+	return NewBoundedWithContext[T](context.Background(), capacity, policy)
+}
+
+// NewBoundedWithContext[T] creates a new Queue[T] with maximum length
+// and returns a pointer to it.
+// When the queue is full, the input value is handled according to policy.
+// If capacity is not positive, the queue is unbounded.
+// If ctx is cancelled, Queue[T] will not consumed input channel,
+// and the input channel will be blocked, however,
+// remained values still will be put into output channel.
+func NewBoundedWithContext[T any](ctx context.Context, capacity int, policy OverflowPolicy) *Queue[T] {
+	return newQueue[T](ctx, newRingBuffer[T](), capacity, policy)
+}
+
+// NewPriority[T] creates a new priority Queue[T] and returns a pointer to it.
+// Values are put into output channel in ascending order of less,
+// instead of FIFO order.
+func NewPriority[T any](less func(a, b T) bool) *Queue[T] {
+	return NewPriorityWithContext[T](context.Background(), less)
+}
+
+// NewPriorityWithContext[T] creates a new priority Queue[T] and returns a pointer to it.
+// Values are put into output channel in ascending order of less,
+// instead of FIFO order.
+// If ctx is cancelled, Queue[T] will not consumed input channel,
+// and the input channel will be blocked, however,
+// remained values still will be put into output channel.
+func NewPriorityWithContext[T any](ctx context.Context, less func(a, b T) bool) *Queue[T] {
+	return newQueue[T](ctx, &heapBuffer[T]{ s: make([]T, 0), less: less }, 0, Block)
+}
+
+// newQueue[T] creates a new Queue[T] backed by buf and returns a pointer to it.
+func newQueue[T any](ctx context.Context, buf buffer[T], capacity int, policy OverflowPolicy) *Queue[T] {
 	in := make(chan T, 0)
 	out := make(chan T, 0)
+	done := make(chan struct{})
+	flushed := make(chan struct{})
 	ctx, cause := context.WithCancelCause(ctx)
+	st := &stats{}
+	buf = &statBuffer[T]{ buffer: buf, stats: st }
 
 	go func(in <- chan T, out chan <- T){
-		defer cause(ErrUnknown)
+		defer close(flushed)
 		defer close(out)
-		queue := make([]T, 0)
 
 		LOOP:
 		for {
-			if len(queue) == 0 {
-				select {
-				case <- ctx.Done():
-					return
-				case v, ok := <- in:
-					if !ok {
-						// `in` is closed
-						// No cleanup is needed
-						cause(ErrInputClosed)
-						return
-					}
-					queue = append(queue, v)
-				}
+			// nil channel blocks forever, so that it is never selected.
+			var recv <- chan T = in
+			if (capacity > 0) && (buf.Len() >= capacity) && (policy == Block) {
+				recv = nil
+			}
+
+			var send chan <- T
+			var head T
+			if buf.Len() > 0 {
+				send = out
+				head = buf.Peek()
 			}
 
 			select {
 			case <- ctx.Done():
 				break LOOP
-			case v, ok := <- in:
-				if ok {
-					queue = append(queue, v)
-				} else {
+			case v, ok := <- recv:
+				if !ok {
 					// `in` is closed
 					// Go to cleanup
 					cause(ErrInputClosed)
 					break LOOP
 				}
-			case out <- queue[0]:
-				queue = queue[1:]
+
+				if (capacity > 0) && (buf.Len() >= capacity) {
+					switch policy {
+					case DropNewest:
+						st.dropped.Add(1)
+						continue LOOP
+					case DropOldest:
+						st.dropped.Add(1)
+						buf.Pop()
+					default:
+						buf.Push(v)
+						cause(ErrOverflow)
+						break LOOP
+					}
+				}
+				buf.Push(v)
+			case send <- head:
+				buf.Pop()
 			}
 		}
 
-		// ctx shoule have already been cancelled, however, we ensure canncel it.
-		cause(ErrUnknown)
+		// ctx has already been cancelled with its reason at every break.
+		close(done)
 
 		// Clean up
-		for len(queue) > 0 {
-			out <- queue[0]
-			queue = queue[1:]
+		for buf.Len() > 0 {
+			out <- buf.Peek()
+			buf.Pop()
 		}
 	}(in, out)
 
-	return &Queue[T]{ in: in, out: out, ctx: ctx }
+	return &Queue[T]{
+		in: in,
+		out: out,
+		ctx: ctx,
+		cause: cause,
+		done: done,
+		flushed: flushed,
+		stats: st,
+	}
 }
 
 // In returns input channel.
@@ -100,10 +234,68 @@ func (q *Queue[T]) Out() <- chan T {
 // Done returns done channel, which will be closed
 // when Queue[T] stops consuming its input channel.
 func (q *Queue[T]) Done() <- chan struct{} {
-	return q.ctx.Done()
+	return q.done
 }
 
 // Error returns error explaining cancel reason.
+// ErrInputClosed, ErrOverflow, ErrClosed, or ErrDrained is returned
+// when Queue[T] stops by itself, and context.Cause(ctx) is returned
+// when the parent context is cancelled.
+// Before Queue[T] stops, nil is returned.
 func (q *Queue[T]) Error() error {
 	return context.Cause(q.ctx)
 }
+
+// Len returns the number of buffered values.
+func (q *Queue[T]) Len() int {
+	return int(q.stats.len.Load())
+}
+
+// Stats returns statistics of Queue[T].
+func (q *Queue[T]) Stats() Stats {
+	return Stats{
+		Len: int(q.stats.len.Load()),
+		HighWaterMark: int(q.stats.hwm.Load()),
+		Dropped: int(q.stats.dropped.Load()),
+	}
+}
+
+// Close stops consuming input channel and flushes remained values.
+// Close blocks until all of them are put into output channel
+// and output channel is closed, so that output channel must be consumed
+// concurrently. Use Drain to take remained values without consumers.
+// Error() returns ErrClosed unless Queue[T] has already stopped.
+// If Queue[T] has already stopped by other reason than
+// ErrClosed or ErrInputClosed (e.g. ErrOverflow or parent context),
+// the reason is returned.
+// Input channel is not closed by Close.
+func (q *Queue[T]) Close() error {
+	q.cause(ErrClosed)
+	<- q.flushed
+
+	if err := q.Error(); !errors.Is(err, ErrClosed) && !errors.Is(err, ErrInputClosed) {
+		return err
+	}
+	return nil
+}
+
+// Drain stops consuming input channel and returns remained values.
+// Error() returns ErrDrained unless Queue[T] has already stopped.
+// If ctx is cancelled, Drain returns values received so far
+// and context.Cause(ctx) error.
+func (q *Queue[T]) Drain(ctx context.Context) ([]T, error) {
+	q.cause(ErrDrained)
+
+	values := make([]T, 0, q.Len())
+	for {
+		select {
+		case v, ok := <- q.out:
+			if !ok {
+				return values, nil
+			}
+			values = append(values, v)
+		case <- ctx.Done():
+			return values, context.Cause(ctx)
+		}
+	}
+}
@@ -57,6 +57,7 @@ func TestQueue(t *testing.T){
 
 func TestQueueContext(t *testing.T){
 	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 	q := NewWithContext[int](ctx)
 
 	for i := 0; i < 5; i++ {
@@ -100,3 +101,246 @@ func TestQueueContext(t *testing.T){
 		return
 	}
 }
+
+func TestBoundedQueue(t *testing.T){
+	for _, policy := range []OverflowPolicy{ DropNewest, DropOldest } {
+		q := NewBounded[int](3, policy)
+
+		for i := 0; i < 5; i++ {
+			select {
+			case q.In() <- i:
+			case <- q.Done():
+				t.Errorf("Fail: %d\n", i)
+				return
+			}
+		}
+		close(q.In())
+
+		want := []int{0, 1, 2}
+		if policy == DropOldest {
+			want = []int{2, 3, 4}
+		}
+
+		for _, w := range want {
+			v, ok := <- q.Out()
+			if !ok || (v != w) {
+				t.Errorf("Fail: %v, %d (want %d)\n", ok, v, w)
+				return
+			}
+		}
+
+		_, ok := <- q.Out()
+		if ok {
+			t.Errorf("Fail\n")
+			return
+		}
+	}
+}
+
+func TestBoundedQueueBlock(t *testing.T){
+	q := NewBounded[int](2, Block)
+
+	for i := 0; i < 2; i++ {
+		q.In() <- i
+	}
+
+	select {
+	case q.In() <- 2:
+		t.Errorf("Must Block\n")
+		return
+	case <- time.After(time.Duration(1000000)):
+	}
+
+	if v := <- q.Out(); v != 0 {
+		t.Errorf("Fail: %d\n", v)
+		return
+	}
+
+	select {
+	case q.In() <- 2:
+	case <- time.After(time.Duration(100000000)):
+		t.Errorf("Must not Block\n")
+		return
+	}
+}
+
+func TestBoundedQueueFail(t *testing.T){
+	q := NewBounded[int](2, Fail)
+
+	for i := 0; i < 3; i++ {
+		q.In() <- i
+	}
+	<- q.Done()
+
+	if !errors.Is(q.Error(), ErrOverflow) {
+		t.Errorf("Must be ErrOverflow: %v\n", q.Error())
+		return
+	}
+
+	// The overflowing value is not lost.
+	for i := 0; i < 3; i++ {
+		v, ok := <- q.Out()
+		if !ok || (v != i) {
+			t.Errorf("Fail: %v, %d\n", ok, v)
+			return
+		}
+	}
+
+	_, ok := <- q.Out()
+	if ok {
+		t.Errorf("Fail\n")
+		return
+	}
+
+	if s := q.Stats(); s.Dropped != 0 {
+		t.Errorf("Must not Drop: %+v\n", s)
+		return
+	}
+}
+
+func TestPriorityQueue(t *testing.T){
+	q := NewPriority[int](func(a, b int) bool { return a > b })
+
+	for _, v := range []int{3, 1, 4, 1, 5, 9, 2, 6} {
+		q.In() <- v
+	}
+	close(q.In())
+
+	for _, w := range []int{9, 6, 5, 4, 3, 2, 1, 1} {
+		v, ok := <- q.Out()
+		if !ok || (v != w) {
+			t.Errorf("Fail: %v, %d (want %d)\n", ok, v, w)
+			return
+		}
+	}
+
+	_, ok := <- q.Out()
+	if ok {
+		t.Errorf("Fail\n")
+		return
+	}
+}
+
+func TestQueueStats(t *testing.T){
+	q := NewBounded[int](3, DropNewest)
+
+	for i := 0; i < 5; i++ {
+		q.In() <- i
+	}
+
+	close(q.In())
+	<- q.Done()
+
+	s := q.Stats()
+	if (s.Len != 3) || (s.HighWaterMark != 3) || (s.Dropped != 2) {
+		t.Errorf("Wrong Stats: %+v\n", s)
+		return
+	}
+
+	for range q.Out() {
+	}
+	if q.Len() != 0 {
+		t.Errorf("Wrong Len: %d\n", q.Len())
+		return
+	}
+	if s := q.Stats(); s.HighWaterMark != 3 {
+		t.Errorf("Wrong HighWaterMark: %d\n", s.HighWaterMark)
+		return
+	}
+}
+
+func TestQueueClose(t *testing.T){
+	q := New[int]()
+
+	for i := 0; i < 3; i++ {
+		q.In() <- i
+	}
+
+	received := make(chan []int)
+	go func(){
+		values := make([]int, 0)
+		for v := range q.Out() {
+			values = append(values, v)
+		}
+		received <- values
+	}()
+
+	if err := q.Close(); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	if !errors.Is(q.Error(), ErrClosed) {
+		t.Errorf("Must be ErrClosed: %v\n", q.Error())
+		return
+	}
+
+	select {
+	case q.In() <- -1:
+		t.Errorf("Must not consume input\n")
+		return
+	case <- time.After(time.Duration(5000)):
+	}
+
+	// Remained values have been flushed when Close returns.
+	values := <- received
+	if len(values) != 3 {
+		t.Errorf("Wrong Length: %d\n", len(values))
+		return
+	}
+	for i, v := range values {
+		if v != i {
+			t.Errorf("Fail: %d (want %d)\n", v, i)
+			return
+		}
+	}
+}
+
+func TestQueueCloseCause(t *testing.T){
+	ctx, cancel := context.WithCancel(context.Background())
+	q := NewWithContext[int](ctx)
+
+	q.In() <- 1
+	cancel()
+	<- q.Done()
+
+	go func(){
+		for range q.Out() {
+		}
+	}()
+
+	if err := q.Close(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Must be context.Canceled: %v\n", err)
+		return
+	}
+}
+
+func TestQueueDrain(t *testing.T){
+	q := New[int]()
+
+	for i := 0; i < 3; i++ {
+		q.In() <- i
+	}
+
+	values, err := q.Drain(context.Background())
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	if len(values) != 3 {
+		t.Errorf("Wrong Length: %d\n", len(values))
+		return
+	}
+	for i, v := range values {
+		if v != i {
+			t.Errorf("Fail: %d (want %d)\n", v, i)
+			return
+		}
+	}
+
+	if !errors.Is(q.Error(), ErrDrained) {
+		t.Errorf("Must be ErrDrained: %v\n", q.Error())
+		return
+	}
+}
@@ -0,0 +1,327 @@
+package qchan
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+type (
+	// segment is an append-only file, which stores put and ack records.
+	// Each segment starts with a mark record of the next sequence number,
+	// so that sequence numbers are never reused after compaction.
+	segment struct {
+		path string
+		pending int
+	}
+
+	// segmentFile is file interface for segment.
+	// It is satisfied by *os.File.
+	segmentFile interface {
+		io.WriteSeeker
+		Sync() error
+		Truncate(size int64) error
+		Close() error
+	}
+
+	// record is a put record which has not been acknowledged yet.
+	record struct {
+		seq uint64
+		payload []byte
+	}
+
+	// segmentLog manages segment files in a directory.
+	segmentLog struct {
+		mu sync.Mutex
+		dir string
+		segments []*segment
+		bySeq map[uint64]*segment
+		cur segmentFile
+		size int64
+		next uint64
+		closed bool
+		failed error
+	}
+)
+
+const (
+	recordPut byte = iota + 1
+	recordAck
+	recordMark
+
+	// recordHeaderSize is size of kind (1 byte), seq (8 bytes), and length (4 bytes).
+	recordHeaderSize = 1 + 8 + 4
+
+	segmentExt = ".seg"
+)
+
+var (
+	// maxSegmentSize is the size to rotate segment file.
+	// It is variable only for test.
+	maxSegmentSize int64 = 4 << 20
+
+	errBrokenRecord = errors.New("Broken record")
+	errSegmentFailed = errors.New("Segment failed")
+)
+
+
+// openSegmentLog opens segment files in dir, and returns unacknowledged records.
+// Broken record at the tail of segment, which is caused by crash, is ignored.
+func openSegmentLog(dir string) (*segmentLog, []record, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("Fail to Create Directory: %w", err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*" + segmentExt))
+	if err != nil {
+		return nil, nil, fmt.Errorf("Fail to List Segments: %w", err)
+	}
+	sort.Strings(paths)
+
+	l := &segmentLog{
+		dir: dir,
+		segments: make([]*segment, 0, len(paths) + 1),
+		bySeq: map[uint64]*segment{},
+		next: 1,
+	}
+	payloads := map[uint64][]byte{}
+
+	for _, p := range paths {
+		s := &segment{ path: p }
+		l.segments = append(l.segments, s)
+
+		err := readSegment(p, func(kind byte, seq uint64, payload []byte){
+			switch kind {
+			case recordPut:
+				payloads[seq] = payload
+				l.bySeq[seq] = s
+				s.pending += 1
+				if seq >= l.next {
+					l.next = seq + 1
+				}
+			case recordAck:
+				if t, ok := l.bySeq[seq]; ok {
+					t.pending -= 1
+					delete(l.bySeq, seq)
+					delete(payloads, seq)
+				}
+			case recordMark:
+				if seq > l.next {
+					l.next = seq
+				}
+			}
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	records := make([]record, 0, len(payloads))
+	for seq, payload := range payloads {
+		records = append(records, record{ seq: seq, payload: payload })
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].seq < records[j].seq
+	})
+
+	// New records are always appended to a new segment,
+	// so that we never write after broken tail.
+	if err := l.rotate(); err != nil {
+		return nil, nil, err
+	}
+
+	return l, records, nil
+}
+
+// readSegment reads all records in segment file at path.
+func readSegment(path string, f func(kind byte, seq uint64, payload []byte)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("Fail to Open Segment: %w", err)
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	var header [recordHeaderSize]byte
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if (err == io.EOF) || (err == io.ErrUnexpectedEOF) {
+				return nil
+			}
+			return fmt.Errorf("Fail to Read Segment: %w", err)
+		}
+
+		kind := header[0]
+		seq := binary.BigEndian.Uint64(header[1:9])
+		n := binary.BigEndian.Uint32(header[9:])
+		if kind == 0 {
+			// Zero filled tail
+			return nil
+		}
+		if (kind != recordPut) && (kind != recordAck) && (kind != recordMark) {
+			return fmt.Errorf("Fail to Read Segment %s: %w", path, errBrokenRecord)
+		}
+
+		payload := make([]byte, n)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			if (err == io.EOF) || (err == io.ErrUnexpectedEOF) {
+				return nil
+			}
+			return fmt.Errorf("Fail to Read Segment: %w", err)
+		}
+
+		f(kind, seq, payload)
+	}
+}
+
+// rotate closes current segment and creates a new one.
+// It must be called with lock (or before sharing segmentLog).
+func (l *segmentLog) rotate() error {
+	if l.cur != nil {
+		if err := l.cur.Close(); err != nil {
+			return fmt.Errorf("Fail to Close Segment: %w", err)
+		}
+		l.cur = nil
+	}
+
+	id := 0
+	if n := len(l.segments); n > 0 {
+		last := filepath.Base(l.segments[n-1].path)
+		fmt.Sscanf(last, "%d", &id)
+	}
+
+	path := filepath.Join(l.dir, fmt.Sprintf("%020d%s", id + 1, segmentExt))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return fmt.Errorf("Fail to Create Segment: %w", err)
+	}
+
+	l.cur = f
+	l.size = 0
+	l.segments = append(l.segments, &segment{ path: path })
+
+	// Old segments must not be removed until the mark is written.
+	if err := l.write(recordMark, l.next, nil); err != nil {
+		return err
+	}
+	l.compact()
+	return nil
+}
+
+// compact removes the oldest segments whose records are all acknowledged.
+// Ack records in a segment refer only to the same or older segments,
+// so that only the oldest ones can be removed safely.
+func (l *segmentLog) compact() {
+	for (len(l.segments) > 1) && (l.segments[0].pending == 0) {
+		os.Remove(l.segments[0].path)
+		l.segments = l.segments[1:]
+	}
+}
+
+// write appends a record to current segment and syncs it.
+// On failure, partially written record is truncated,
+// so that following records are never appended after it.
+// If it cannot be truncated, the log rejects further writes.
+func (l *segmentLog) write(kind byte, seq uint64, payload []byte) error {
+	if l.closed {
+		return ErrClosed
+	}
+	if l.failed != nil {
+		return fmt.Errorf("%w: %w", errSegmentFailed, l.failed)
+	}
+
+	buf := make([]byte, recordHeaderSize + len(payload))
+	buf[0] = kind
+	binary.BigEndian.PutUint64(buf[1:9], seq)
+	binary.BigEndian.PutUint32(buf[9:recordHeaderSize], uint32(len(payload)))
+	copy(buf[recordHeaderSize:], payload)
+
+	if _, err := l.cur.Write(buf); err != nil {
+		l.discard()
+		return fmt.Errorf("Fail to Write Segment: %w", err)
+	}
+	if err := l.cur.Sync(); err != nil {
+		l.discard()
+		return fmt.Errorf("Fail to Sync Segment: %w", err)
+	}
+	l.size += int64(len(buf))
+
+	return nil
+}
+
+// discard truncates current segment back to the last complete record.
+func (l *segmentLog) discard() {
+	if err := l.cur.Truncate(l.size); err != nil {
+		l.failed = fmt.Errorf("Fail to Truncate Segment: %w", err)
+		return
+	}
+	if _, err := l.cur.Seek(l.size, io.SeekStart); err != nil {
+		l.failed = fmt.Errorf("Fail to Seek Segment: %w", err)
+	}
+}
+
+// put appends a put record and returns its sequence number.
+func (l *segmentLog) put(payload []byte) (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	seq := l.next
+	if err := l.write(recordPut, seq, payload); err != nil {
+		return 0, err
+	}
+	l.next += 1
+
+	s := l.segments[len(l.segments)-1]
+	s.pending += 1
+	l.bySeq[seq] = s
+
+	if l.size >= maxSegmentSize {
+		return seq, l.rotate()
+	}
+	return seq, nil
+}
+
+// ack appends an ack record for seq.
+func (l *segmentLog) ack(seq uint64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s, ok := l.bySeq[seq]
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrNotPending, seq)
+	}
+
+	if err := l.write(recordAck, seq, nil); err != nil {
+		return err
+	}
+	s.pending -= 1
+	delete(l.bySeq, seq)
+
+	if l.size >= maxSegmentSize {
+		return l.rotate()
+	}
+	l.compact()
+	return nil
+}
+
+// close closes current segment.
+func (l *segmentLog) close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+
+	if err := l.cur.Close(); err != nil {
+		return fmt.Errorf("Fail to Close Segment: %w", err)
+	}
+	return nil
+}
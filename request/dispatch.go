@@ -1,25 +1,112 @@
 package request
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ymd-h/go/encoding"
 )
 
 type (
+	// Interface for Dispatch Rule
+	//
+	// `IDispatchItem` matches exact status code,
+	// `IDispatchMatcher` matches arbitrary condition,
+	// and `IDispatchDefault` is used when no other rules match.
+	IDispatchRule interface {
+		Response() any
+	}
+
 	// Interface for Dispatch Item
 	IDispatchItem interface {
 		StatusCode() int
 		Response() any
 	}
 
+	// Interface for Dispatch Rule with custom condition
+	IDispatchMatcher interface {
+		Match(code int, contentType string) bool
+		Response() any
+	}
+
+	// Interface for Default Dispatch Rule
+	IDispatchDefault interface {
+		IsDefault() bool
+		Response() any
+	}
+
+	// Interface for Dispatch Rule which marks response as error
+	IErrorDispatchRule interface {
+		IsError() bool
+	}
+
 	// Dispatch Item
 	DispatchItem[T any] struct {
 		Code int
 	}
 
+	// Dispatch Item for status code range [Min, Max]
+	DispatchRange[T any] struct {
+		Min int
+		Max int
+	}
+
+	// Dispatch Item for media type of `Content-Type`.
+	// Wildcard subtype like "application/*" is allowed.
+	DispatchContentType[T any] struct {
+		ContentType string
+	}
+
+	// Dispatch Item used when no other rules match
+	DispatchDefault[T any] struct {}
+
 	// Response Dispatcher class
+	//
+	// Rules are checked in the following order;
+	// exact status code, matchers in added order, and default.
 	ResponseDispatcher struct {
 		rule map[int] IDispatchItem
+		matchers []IDispatchMatcher
+		fallback IDispatchDefault
+	}
+
+	// HTTP Error returned for response dispatched to error rule
+	//
+	// It matches `ErrHTTPStatus` with `errors.Is()`,
+	// and decoded body, too, if it is `error`.
+	HTTPError struct {
+		Method string
+		URL string
+		StatusCode int
+		Header http.Header
+
+		// Decoded response body
+		Body any
+	}
+
+	errorItem struct {
+		IDispatchItem
+	}
+
+	errorMatcher struct {
+		IDispatchMatcher
+	}
+
+	errorDefault struct {
+		IDispatchDefault
 	}
+
+	errorRule struct {
+		IDispatchRule
+	}
+)
+
+var (
+	ErrNoDispatchRule = errors.New("No dispatch rule")
+	ErrUnknownDispatchRule = errors.New("Unknown dispatch rule")
+	ErrHTTPStatus = errors.New("HTTP error status")
 )
 
 
@@ -32,6 +119,117 @@ func (i DispatchItem[T]) Response() any {
 	return &v
 }
 
+func (i DispatchRange[T]) Match(code int, _ string) bool {
+	return (i.Min <= code) && (code <= i.Max)
+}
+
+func (i DispatchRange[T]) Response() any {
+	var v T
+	return &v
+}
+
+func (i DispatchContentType[T]) Match(_ int, contentType string) bool {
+	if contentType == "" {
+		return false
+	}
+
+	mediaType, _, err := encoding.ParseContentType(contentType)
+	if err != nil {
+		return false
+	}
+
+	want := strings.ToLower(i.ContentType)
+	if prefix, ok := strings.CutSuffix(want, "/*"); ok {
+		return strings.HasPrefix(mediaType, prefix + "/")
+	}
+	return mediaType == want
+}
+
+func (i DispatchContentType[T]) Response() any {
+	var v T
+	return &v
+}
+
+func (_ DispatchDefault[T]) IsDefault() bool {
+	return true
+}
+
+func (_ DispatchDefault[T]) Response() any {
+	var v T
+	return &v
+}
+
+func (_ errorItem) IsError() bool {
+	return true
+}
+
+func (_ errorMatcher) IsError() bool {
+	return true
+}
+
+func (_ errorDefault) IsError() bool {
+	return true
+}
+
+func (_ errorRule) IsError() bool {
+	return true
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%s at %s: %d %s",
+		e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Unwrap returns `ErrHTTPStatus`, and decoded body if it is `error`.
+func (e *HTTPError) Unwrap() []error {
+	errs := []error{ ErrHTTPStatus }
+	if err, ok := e.Body.(error); ok {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+
+// Create 2xx, 3xx, 4xx or 5xx Dispatch Item
+//
+// # Arguments
+// * `class`: `int` - Status class like `4` for 4xx
+//
+// # Returns
+// * `DispatchRange[T]` - Dispatch Item
+func DispatchClass[T any](class int) DispatchRange[T] {
+	return DispatchRange[T]{ Min: class * 100, Max: class * 100 + 99 }
+}
+
+// Mark Dispatch Rule as error
+//
+// When response is dispatched to the rule,
+// `FetchWithContext()` decodes body and returns `*HTTPError` carrying it.
+//
+// # Arguments
+// * `rule`: `IDispatchRule` - Dispatch Rule
+//
+// # Returns
+// * `IDispatchRule` - Dispatch Rule marked as error
+func AsError(rule IDispatchRule) IDispatchRule {
+	switch r := rule.(type) {
+	case IDispatchItem:
+		return errorItem{ r }
+	case IDispatchMatcher:
+		return errorMatcher{ r }
+	case IDispatchDefault:
+		return errorDefault{ r }
+	default:
+		return errorRule{ r }
+	}
+}
+
+// isErrorRule returns true if rule is marked as error.
+func isErrorRule(rule IDispatchRule) bool {
+	e, ok := rule.(IErrorDispatchRule)
+	return ok && e.IsError()
+}
+
 func NewResponseDispatcher(items ...IDispatchItem) *ResponseDispatcher {
 	d := &ResponseDispatcher{rule: make(map[int] IDispatchItem, len(items))}
 
@@ -40,22 +238,85 @@ func NewResponseDispatcher(items ...IDispatchItem) *ResponseDispatcher {
 	return d
 }
 
+// Create new ResponseDispatcher with Dispatch Rules
+//
+// # Arguments
+// * `rules`: `...IDispatchRule` - Dispatch Rules like `DispatchRange[T]`
+//
+// # Returns
+// * `*ResponseDispatcher` - Created ResponseDispatcher
+// * `error` - `ErrUnknownDispatchRule` for unknown rule
+func NewResponseDispatcherWithRules(rules ...IDispatchRule) (*ResponseDispatcher, error) {
+	d := NewResponseDispatcher()
+	if err := d.AddRule(rules...); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
 func (d *ResponseDispatcher) Add(items ...IDispatchItem) {
 	for _, i := range items {
 		d.rule[i.StatusCode()] = i
 	}
 }
 
+// AddRule adds Dispatch Rules.
+// Exact status code and default are replaced by the later one.
+// If any rule is unknown, no rules are added
+// and `ErrUnknownDispatchRule` is returned.
+func (d *ResponseDispatcher) AddRule(rules ...IDispatchRule) error {
+	for _, r := range rules {
+		switch r.(type) {
+		case IDispatchItem, IDispatchMatcher, IDispatchDefault:
+		default:
+			return fmt.Errorf("%w: %T", ErrUnknownDispatchRule, r)
+		}
+	}
+
+	for _, r := range rules {
+		switch r := r.(type) {
+		case IDispatchItem:
+			d.rule[r.StatusCode()] = r
+		case IDispatchMatcher:
+			d.matchers = append(d.matchers, r)
+		case IDispatchDefault:
+			d.fallback = r
+		}
+	}
+	return nil
+}
+
+// Delete deletes exact status code rules.
 func (d *ResponseDispatcher) Delete(codes ...int) {
 	for _, i := range codes {
 		delete(d.rule, i)
 	}
 }
 
-func (d *ResponseDispatcher) Dispatch(code int) (any, error) {
+// Lookup returns rule for status code and `Content-Type`.
+func (d *ResponseDispatcher) Lookup(code int, contentType string) (IDispatchRule, error) {
 	if r, ok := d.rule[code]; ok {
-		return r.Response(), nil
+		return r, nil
+	}
+
+	for _, m := range d.matchers {
+		if m.Match(code, contentType) {
+			return m, nil
+		}
+	}
+
+	if d.fallback != nil {
+		return d.fallback, nil
+	}
+
+	return nil, ErrNoDispatchRule
+}
+
+func (d *ResponseDispatcher) Dispatch(code int) (any, error) {
+	r, err := d.Lookup(code, "")
+	if err != nil {
+		return nil, fmt.Errorf("Fail to Dispatch StatusCode: %d: %w", code, err)
 	}
 
-	return nil, fmt.Errorf("Fail to Dispatch StatusCode: %d", code)
+	return r.Response(), nil
 }
@@ -1,7 +1,12 @@
 package request
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
 	"testing"
+
+	"github.com/ymd-h/go/request/json"
 )
 
 
@@ -54,3 +59,213 @@ func TestDispatcher(t *testing.T){
 		return
 	}
 }
+
+func TestDispatcherRules(t *testing.T){
+	type (
+		A struct {}
+		B struct {}
+		C struct {}
+		D struct {}
+		E struct {}
+	)
+
+	d, err := NewResponseDispatcherWithRules(
+		DispatchItem[A]{200},
+		DispatchContentType[B]{"application/problem+json"},
+		DispatchClass[C](2),
+		DispatchRange[D]{400, 599},
+	)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	for _, tc := range []struct{
+		code int
+		contentType string
+		want any
+	}{
+		{ code: 200, contentType: "application/problem+json", want: &A{} },
+		{ code: 201, contentType: "application/json", want: &C{} },
+		{ code: 404, contentType: "application/problem+json; charset=utf-8", want: &B{} },
+		{ code: 404, contentType: "application/json", want: &D{} },
+		{ code: 503, contentType: "", want: &D{} },
+	}{
+		r, err := d.Lookup(tc.code, tc.contentType)
+		if err != nil {
+			t.Errorf("Fail: %v\n", err)
+			return
+		}
+		if got, want := fmt.Sprintf("%T", r.Response()), fmt.Sprintf("%T", tc.want); got != want {
+			t.Errorf("Fail: %d %s: %s != %s\n", tc.code, tc.contentType, got, want)
+			return
+		}
+	}
+
+	if _, err := d.Lookup(302, ""); !errors.Is(err, ErrNoDispatchRule) {
+		t.Errorf("Must Fail\n")
+		return
+	}
+
+	if err := d.AddRule(DispatchDefault[E]{}); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	e, err := d.Dispatch(302)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if _, ok := e.(*E); !ok {
+		t.Errorf("Fail: %T\n", e)
+		return
+	}
+
+	m := DispatchContentType[A]{"text/*"}
+	if !m.Match(200, "text/plain; charset=utf-8") || m.Match(200, "application/json") {
+		t.Errorf("Fail: wildcard\n")
+		return
+	}
+}
+
+func TestDispatcherError(t *testing.T){
+	type (
+		OK struct {
+			A int `json:"a"`
+		}
+		Problem struct {
+			Title string `json:"title"`
+		}
+	)
+
+	f := &fakeClient{
+		responses: []fakeResponse{
+			{
+				code: http.StatusNotFound,
+				header: http.Header{
+					"Content-Type": []string{"application/problem+json"},
+				},
+				body: `{"title":"Not Found"}`,
+			},
+			{ code: http.StatusOK, body: `{"a":1}` },
+		},
+	}
+	c := NewClient(f, json.Encoder{}, json.Decoder{})
+
+	d, err := NewResponseDispatcherWithRules(
+		DispatchClass[OK](2),
+		AsError(DispatchContentType[Problem]{"application/problem+json"}),
+	)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	resp, err := c.Get("http://example.com/", d)
+	var herr *HTTPError
+	if !errors.As(err, &herr) {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if herr.StatusCode != http.StatusNotFound {
+		t.Errorf("Fail: %d\n", herr.StatusCode)
+		return
+	}
+	if !errors.Is(err, ErrHTTPStatus) {
+		t.Errorf("Must be ErrHTTPStatus: %v\n", err)
+		return
+	}
+	if msg := herr.Error(); msg != "GET at http://example.com/: 404 Not Found" {
+		t.Errorf("Fail: %s\n", msg)
+		return
+	}
+	if p, ok := herr.Body.(*Problem); !ok || (p.Title != "Not Found") {
+		t.Errorf("Fail: %v\n", herr.Body)
+		return
+	}
+	if resp.Body != herr.Body {
+		t.Errorf("Fail: %v\n", resp.Body)
+		return
+	}
+
+	resp, err = c.Get("http://example.com/", d)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if o, ok := resp.Body.(*OK); !ok || (o.A != 1) {
+		t.Errorf("Fail: %v\n", resp.Body)
+		return
+	}
+
+	// Wrapped rules keep their kind
+	if _, ok := AsError(DispatchItem[OK]{500}).(IDispatchItem); !ok {
+		t.Errorf("Fail: AsError(DispatchItem)\n")
+		return
+	}
+	if _, ok := AsError(DispatchDefault[OK]{}).(IDispatchDefault); !ok {
+		t.Errorf("Fail: AsError(DispatchDefault)\n")
+		return
+	}
+}
+
+type unknownRule struct {}
+
+func (_ unknownRule) Response() any {
+	return nil
+}
+
+func TestDispatcherUnknownRule(t *testing.T){
+	type A struct {}
+
+	// Existing entry points accept []IDispatchItem.
+	items := []IDispatchItem{ DispatchItem[A]{200} }
+	d := NewResponseDispatcher(items...)
+	d.Add(items...)
+
+	if err := d.AddRule(DispatchClass[A](4), unknownRule{}); !errors.Is(err, ErrUnknownDispatchRule) {
+		t.Errorf("Must be ErrUnknownDispatchRule: %v\n", err)
+		return
+	}
+
+	// Nothing is added on error.
+	if _, err := d.Lookup(404, ""); !errors.Is(err, ErrNoDispatchRule) {
+		t.Errorf("Must be ErrNoDispatchRule: %v\n", err)
+		return
+	}
+
+	if _, err := NewResponseDispatcherWithRules(AsError(unknownRule{})); !errors.Is(err, ErrUnknownDispatchRule) {
+		t.Errorf("Must be ErrUnknownDispatchRule: %v\n", err)
+		return
+	}
+}
+
+type errorBody struct {
+	Message string `json:"message"`
+}
+
+func (e *errorBody) Error() string {
+	return e.Message
+}
+
+func TestHTTPErrorUnwrap(t *testing.T){
+	f := &fakeClient{
+		responses: []fakeResponse{
+			{ code: http.StatusBadRequest, body: `{"message":"bad"}` },
+		},
+	}
+	c := NewClient(f, json.Encoder{}, json.Decoder{})
+
+	d, _ := NewResponseDispatcherWithRules(AsError(DispatchDefault[errorBody]{}))
+
+	_, err := c.Get("http://example.com/", d)
+	var body *errorBody
+	if !errors.As(err, &body) || (body.Message != "bad") {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if !errors.Is(err, ErrHTTPStatus) {
+		t.Errorf("Must be ErrHTTPStatus: %v\n", err)
+		return
+	}
+}
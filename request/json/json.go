@@ -13,5 +13,5 @@ type (
 
 
 func (_ Encoder) ContentType() string {
-	return "application/json"
+	return json.MediaType
 }
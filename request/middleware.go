@@ -0,0 +1,107 @@
+package request
+
+import (
+	"io"
+	"net/http"
+)
+
+type (
+	// Function sending HTTP request like `http.Client.Do`
+	DoFunc func(*http.Request) (*http.Response, error)
+
+	// Function encoding request body
+	EncodeFunc func(request any) (io.Reader, error)
+
+	// Function decoding response body
+	DecodeFunc func(res *http.Response, response any) error
+
+	// Middleware wrapping `DoFunc` (round-tripper style)
+	//
+	// It is called for each attempt including retries.
+	Middleware func(next DoFunc) DoFunc
+
+	// Middleware wrapping `EncodeFunc`
+	EncodeMiddleware func(next EncodeFunc) EncodeFunc
+
+	// Middleware wrapping `DecodeFunc`
+	DecodeMiddleware func(next DecodeFunc) DecodeFunc
+)
+
+
+// Do implements `IHttpClient`.
+func (f DoFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Client Option for Middlewares
+//
+// Middlewares are applied in order,
+// so that the first one is the outermost.
+// It sees the request first and the response last.
+// Multiple calls append middlewares.
+//
+// # Arguments
+// * `middlewares`: `...Middleware` - Middlewares
+//
+// # Returns
+// * `ClientOption` - Client Option
+func WithMiddleware(middlewares ...Middleware) ClientOption {
+	return func(c *Client){
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+// Client Option for Encode Middlewares
+//
+// The first one is the outermost like `WithMiddleware()`.
+//
+// # Arguments
+// * `middlewares`: `...EncodeMiddleware` - Encode Middlewares
+//
+// # Returns
+// * `ClientOption` - Client Option
+func WithEncodeMiddleware(middlewares ...EncodeMiddleware) ClientOption {
+	return func(c *Client){
+		c.encodeMiddlewares = append(c.encodeMiddlewares, middlewares...)
+	}
+}
+
+// Client Option for Decode Middlewares
+//
+// The first one is the outermost like `WithMiddleware()`.
+//
+// # Arguments
+// * `middlewares`: `...DecodeMiddleware` - Decode Middlewares
+//
+// # Returns
+// * `ClientOption` - Client Option
+func WithDecodeMiddleware(middlewares ...DecodeMiddleware) ClientOption {
+	return func(c *Client){
+		c.decodeMiddlewares = append(c.decodeMiddlewares, middlewares...)
+	}
+}
+
+// buildChain builds middleware chains. It must be called after options.
+func (c *Client) buildChain() {
+	// c.client and c.encoder are looked up at call,
+	// so that nil ones fail only when they are used.
+	do := DoFunc(func(req *http.Request) (*http.Response, error) {
+		return c.client.Do(req)
+	})
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		do = c.middlewares[i](do)
+	}
+	c.doFunc = do
+
+	encode := EncodeFunc(c.encodeBody)
+	for i := len(c.encodeMiddlewares) - 1; i >= 0; i-- {
+		encode = c.encodeMiddlewares[i](encode)
+	}
+	c.encodeFunc = encode
+
+	decode := DecodeFunc(c.decodeBody)
+	for i := len(c.decodeMiddlewares) - 1; i >= 0; i-- {
+		decode = c.decodeMiddlewares[i](decode)
+	}
+	c.decodeFunc = decode
+}
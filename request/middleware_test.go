@@ -0,0 +1,168 @@
+package request
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ymd-h/go/request/json"
+)
+
+func TestMiddleware(t *testing.T){
+	f := &fakeClient{
+		responses: []fakeResponse{
+			{ code: http.StatusServiceUnavailable },
+			{ code: http.StatusOK, body: `1` },
+		},
+	}
+
+	order := make([]string, 0)
+	mw := func(name string) Middleware {
+		return func(next DoFunc) DoFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				order = append(order, name + "-req")
+				req.Header.Add("X-Trace", name)
+				res, err := next(req)
+				order = append(order, name + "-res")
+				return res, err
+			}
+		}
+	}
+
+	c := NewClient(
+		f, json.Encoder{}, json.Decoder{},
+		WithMiddleware(mw("a"), mw("b")),
+		WithMiddleware(mw("c")),
+		WithRetry(RetryPolicy{ MaxAttempts: 2 }),
+	)
+	c.sleep = func(_ context.Context, _ time.Duration) error { return nil }
+
+	var v int
+	if _, err := c.Get("http://example.com/", &v); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	want := []string{
+		"a-req", "b-req", "c-req", "c-res", "b-res", "a-res",
+		"a-req", "b-req", "c-req", "c-res", "b-res", "a-res",
+	}
+	if strings.Join(order, ",") != strings.Join(want, ",") {
+		t.Errorf("Fail: %v\n", order)
+		return
+	}
+
+	if v := f.requests[1].Header.Values("X-Trace"); strings.Join(v, ",") != "a,b,c" {
+		t.Errorf("Fail: %v\n", v)
+		return
+	}
+}
+
+func TestEncodeDecodeMiddleware(t *testing.T){
+	f := &fakeClient{
+		responses: []fakeResponse{ { code: http.StatusOK, body: `"abc"` } },
+	}
+
+	order := make([]string, 0)
+	c := NewClient(
+		f, json.Encoder{}, json.Decoder{},
+		WithEncodeMiddleware(func(next EncodeFunc) EncodeFunc {
+			return func(request any) (io.Reader, error) {
+				order = append(order, "encode")
+				r, err := next(request)
+				if err != nil {
+					return nil, err
+				}
+
+				// e.g. request signing
+				b, err := io.ReadAll(r)
+				if err != nil {
+					return nil, err
+				}
+				return bytes.NewReader(append([]byte("  "), b...)), nil
+			}
+		}),
+		WithDecodeMiddleware(
+			func(next DecodeFunc) DecodeFunc {
+				return func(res *http.Response, response any) error {
+					order = append(order, "decode-outer")
+					return next(res, response)
+				}
+			},
+			func(next DecodeFunc) DecodeFunc {
+				return func(res *http.Response, response any) error {
+					order = append(order, "decode-inner")
+					if res.StatusCode != http.StatusOK {
+						t.Errorf("Fail: %d\n", res.StatusCode)
+					}
+					return next(res, response)
+				}
+			},
+		),
+	)
+
+	var s string
+	if _, err := c.Post("http://example.com/", 1, &s); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	if s != "abc" {
+		t.Errorf("Fail: %s\n", s)
+		return
+	}
+	if f.bodies[0] != "  1\n" {
+		t.Errorf("Fail: %q\n", f.bodies[0])
+		return
+	}
+	if strings.Join(order, ",") != "encode,decode-outer,decode-inner" {
+		t.Errorf("Fail: %v\n", order)
+		return
+	}
+}
+
+func TestDoFunc(t *testing.T){
+	called := false
+	c := NewClient(
+		DoFunc(func(req *http.Request) (*http.Response, error) {
+			called = true
+			return &http.Response{
+				StatusCode: http.StatusNoContent,
+				Header: http.Header{},
+				Body: http.NoBody,
+			}, nil
+		}),
+		json.Encoder{}, json.Decoder{},
+	)
+
+	res, err := c.Head("http://example.com/")
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if !called || (res.StatusCode != http.StatusNoContent) {
+		t.Errorf("Fail: %v %d\n", called, res.StatusCode)
+		return
+	}
+}
+
+func TestNilEncoder(t *testing.T){
+	f := &fakeClient{ responses: []fakeResponse{ { code: http.StatusOK, body: `1` } } }
+	c := NewClient(f, nil, json.Decoder{})
+
+	var v int
+	if _, err := c.Get("http://example.com/", &v); (err != nil) || (v != 1) {
+		t.Errorf("Fail: %v, %d\n", err, v)
+		return
+	}
+
+	if _, err := c.Post("http://example.com/", 1, nil); !errors.Is(err, ErrNoEncoder) {
+		t.Errorf("Must be ErrNoEncoder: %v\n", err)
+		return
+	}
+}
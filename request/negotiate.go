@@ -0,0 +1,139 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ymd-h/go/encoding"
+)
+
+type (
+	// Interface for Response Body Decoder choosing decoder by `Content-Type`
+	//
+	// `Accept()` is sent as `Accept` header.
+	IContentTypeDecoder interface {
+		IBodyDecoder
+		Accept() string
+		DecoderFor(contentType string) (IBodyDecoder, error)
+	}
+
+	// Negotiator is Response Body Decoder choosing decoder by `Content-Type`
+	Negotiator struct {
+		codecs []encoding.Codec
+		registry *encoding.Registry
+		accept string
+	}
+)
+
+var (
+	ErrUnsupportedContentType = errors.New("Unsupported Content-Type")
+	ErrNoCodec = errors.New("No codec")
+)
+
+
+// Create new Negotiator
+//
+// Only `MediaType`, `Aliases` and `Decoder` of codecs are used.
+// The first codec is the most preferred,
+// and is used when response doesn't have `Content-Type`.
+// Default codecs are available at `request/negotiate` subpackage.
+//
+// # Arguments
+// * `codecs`: `...encoding.Codec` - Acceptable Codecs
+//
+// # Returns
+// * `*Negotiator` - Created Negotiator
+// * `error` - Error when codecs are empty or a codec doesn't have Decoder
+func NewNegotiator(codecs ...encoding.Codec) (*Negotiator, error) {
+	if len(codecs) == 0 {
+		return nil, fmt.Errorf("Fail to Create Negotiator: %w", ErrNoCodec)
+	}
+
+	n := &Negotiator{
+		codecs: make([]encoding.Codec, 0, len(codecs)),
+		registry: encoding.NewRegistry(),
+	}
+	accept := make([]string, 0, len(codecs))
+	for i, c := range codecs {
+		if (c.MediaType == "") || (c.Decoder == nil) {
+			return nil, fmt.Errorf("Fail to Create Negotiator: %w: %q", ErrNoCodec, c.MediaType)
+		}
+		n.codecs = append(n.codecs, c)
+		n.registry.Register(c)
+
+		// Decrease q-value by preference order
+		q := 1.0 - 0.1 * float64(i)
+		if q < 0.1 {
+			q = 0.1
+		}
+		if i == 0 {
+			accept = append(accept, c.MediaType)
+		} else {
+			accept = append(accept, c.MediaType + ";q=" + strconv.FormatFloat(q, 'f', 1, 64))
+		}
+	}
+	n.accept = strings.Join(accept, ", ")
+
+	return n, nil
+}
+
+// Create new Negotiator with Codecs in Registry
+//
+// # Arguments
+// * `registry`: `*encoding.Registry` - Codec Registry
+// * `mediaTypes`: `...string` - Acceptable Media Types
+//
+// # Returns
+// * `*Negotiator` - Created Negotiator
+// * `error` - Error when media type is not registered
+func NewNegotiatorWithRegistry(
+	registry *encoding.Registry,
+	mediaTypes ...string,
+) (*Negotiator, error) {
+	codecs := make([]encoding.Codec, 0, len(mediaTypes))
+	for _, t := range mediaTypes {
+		c, err := registry.Lookup(t)
+		if err != nil {
+			return nil, fmt.Errorf("Fail to Create Negotiator: %w", err)
+		}
+		codecs = append(codecs, c)
+	}
+
+	return NewNegotiator(codecs...)
+}
+
+// Accept returns `Accept` header value.
+func (n *Negotiator) Accept() string {
+	return n.accept
+}
+
+// DecoderFor returns decoder for `Content-Type`.
+// If `contentType` is empty, the most preferred one is returned.
+// If `contentType` is not acceptable,
+// `ErrUnsupportedContentType` is returned.
+func (n *Negotiator) DecoderFor(contentType string) (IBodyDecoder, error) {
+	if contentType == "" {
+		return n.codecs[0].Decoder, nil
+	}
+
+	mediaType, _, err := encoding.ParseContentType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrUnsupportedContentType, contentType, err)
+	}
+
+	// Aliases and structured syntax suffix like "+json" are allowed.
+	c, err := n.registry.Lookup(mediaType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedContentType, mediaType)
+	}
+
+	return c.Decoder, nil
+}
+
+// Decode decodes with the most preferred decoder.
+func (n *Negotiator) Decode(r io.Reader, v any) error {
+	return n.codecs[0].Decoder.Decode(r, v)
+}
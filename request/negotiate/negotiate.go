@@ -0,0 +1,33 @@
+// Package request/negotiate provides default codecs of request.Negotiator
+//
+// Importing this package links JSON, XML, gob and plain text decoders.
+package negotiate
+
+import (
+	"github.com/ymd-h/go/encoding"
+	"github.com/ymd-h/go/encoding/gob"
+	"github.com/ymd-h/go/encoding/json"
+	"github.com/ymd-h/go/encoding/text"
+	"github.com/ymd-h/go/encoding/xml"
+	"github.com/ymd-h/go/request"
+)
+
+
+// Default codecs in preference order
+func Codecs() []encoding.Codec {
+	return []encoding.Codec{
+		{ MediaType: json.MediaType, Decoder: json.Decoder{} },
+		{ MediaType: xml.MediaType, Aliases: []string{"text/xml"}, Decoder: xml.Decoder{} },
+		{ MediaType: gob.MediaType, Decoder: gob.Decoder{} },
+		{ MediaType: text.MediaType, Decoder: text.Decoder{} },
+	}
+}
+
+// Create new Negotiator with default codecs
+//
+// # Returns
+// * `*request.Negotiator` - Created Negotiator
+// * `error` - Error
+func New() (*request.Negotiator, error) {
+	return request.NewNegotiator(Codecs()...)
+}
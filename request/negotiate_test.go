@@ -0,0 +1,156 @@
+package request
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ymd-h/go/encoding"
+	ejson "github.com/ymd-h/go/encoding/json"
+	"github.com/ymd-h/go/encoding/text"
+	"github.com/ymd-h/go/encoding/xml"
+	"github.com/ymd-h/go/request/json"
+)
+
+var (
+	negotiateCodecs = []encoding.Codec{
+		{ MediaType: ejson.MediaType, Decoder: ejson.Decoder{} },
+		{ MediaType: xml.MediaType, Aliases: []string{"text/xml"}, Decoder: xml.Decoder{} },
+		{ MediaType: text.MediaType, Decoder: text.Decoder{} },
+	}
+)
+
+type negotiateData struct {
+	A int `json:"a" xml:"a"`
+}
+
+func TestNegotiator(t *testing.T){
+	n, err := NewNegotiator(negotiateCodecs...)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	for _, tc := range []struct{
+		name string
+		contentType string
+		body string
+	}{
+		{ name: "json", contentType: "application/json", body: `{"a":1}` },
+		{ name: "problem+json", contentType: "application/problem+json", body: `{"a":1}` },
+		{ name: "xml", contentType: "application/xml; charset=utf-8", body: `<negotiateData><a>1</a></negotiateData>` },
+		{ name: "text/xml", contentType: "text/xml", body: `<negotiateData><a>1</a></negotiateData>` },
+		{ name: "empty", contentType: "", body: `{"a":1}` },
+	}{
+		tc := tc
+		t.Run(tc.name, func(t *testing.T){
+			header := http.Header{}
+			if tc.contentType != "" {
+				header.Set("Content-Type", tc.contentType)
+			}
+			f := &fakeClient{
+				responses: []fakeResponse{
+					{ code: http.StatusOK, header: header, body: tc.body },
+				},
+			}
+			c := NewClient(f, json.Encoder{}, n)
+
+			var v negotiateData
+			if _, err := c.Get("http://example.com/", &v); err != nil {
+				t.Errorf("Fail: %v\n", err)
+				return
+			}
+			if v.A != 1 {
+				t.Errorf("Fail: %v\n", v)
+				return
+			}
+
+			if a := f.requests[0].Header.Get("Accept"); a != n.Accept() {
+				t.Errorf("Fail: %s\n", a)
+				return
+			}
+		})
+	}
+
+	t.Run("text", func(t *testing.T){
+		f := &fakeClient{
+			responses: []fakeResponse{
+				{
+					code: http.StatusOK,
+					header: http.Header{ "Content-Type": []string{"text/plain; charset=utf-8"} },
+					body: "hello",
+				},
+			},
+		}
+		c := NewClient(f, json.Encoder{}, n)
+
+		var s string
+		if _, err := c.Get("http://example.com/", &s); err != nil {
+			t.Errorf("Fail: %v\n", err)
+			return
+		}
+		if s != "hello" {
+			t.Errorf("Fail: %s\n", s)
+			return
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T){
+		f := &fakeClient{
+			responses: []fakeResponse{
+				{
+					code: http.StatusOK,
+					header: http.Header{ "Content-Type": []string{"image/png"} },
+					body: "png",
+				},
+			},
+		}
+		c := NewClient(f, json.Encoder{}, n)
+
+		var v negotiateData
+		_, err := c.Get("http://example.com/", &v)
+		if !errors.Is(err, ErrUnsupportedContentType) {
+			t.Errorf("Fail: %v\n", err)
+			return
+		}
+	})
+}
+
+func TestNegotiatorAccept(t *testing.T){
+	n, err := NewNegotiator(negotiateCodecs[1], negotiateCodecs[0])
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	if a := n.Accept(); a != "application/xml, application/json;q=0.9" {
+		t.Errorf("Fail: %s\n", a)
+		return
+	}
+
+	// Not in the acceptable list
+	if _, err := n.DecoderFor("text/plain"); !errors.Is(err, ErrUnsupportedContentType) {
+		t.Errorf("Must Fail\n")
+		return
+	}
+
+	if _, err := NewNegotiator(); !errors.Is(err, ErrNoCodec) {
+		t.Errorf("Must be ErrNoCodec: %v\n", err)
+		return
+	}
+	if _, err := NewNegotiator(encoding.Codec{ MediaType: "text/plain" }); !errors.Is(err, ErrNoCodec) {
+		t.Errorf("Must be ErrNoCodec: %v\n", err)
+		return
+	}
+
+	r := encoding.NewRegistry()
+	r.Register(negotiateCodecs[0])
+	if _, err := NewNegotiatorWithRegistry(r, "application/json"); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if _, err := NewNegotiatorWithRegistry(r, "application/xml"); err == nil {
+		t.Errorf("Must Fail\n")
+		return
+	}
+}
@@ -0,0 +1,317 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type (
+	// Request Option applied by `Client.With()`
+	RequestOption func(*requestConfig)
+
+	requestConfig struct {
+		header http.Header
+		query url.Values
+		auth func(*http.Request)
+		timeout time.Duration
+		rawBody bool
+		err error
+	}
+)
+
+var (
+	ErrUnsupportedQuery = errors.New("Unsupported query type")
+)
+
+
+// Create Client with Request Options
+//
+// Returned Client shares everything with the original one,
+// and applies the options to every request in addition to
+// the options of the original one.
+//
+//	c.With(WithBearerToken(token), WithTimeout(time.Second)).Get(url, &v)
+//
+// # Arguments
+// * `options`: `...RequestOption` - Request Options like `WithHeader()`
+//
+// # Returns
+// * `*Client` - Client with Request Options
+func (c *Client) With(options ...RequestOption) *Client {
+	cc := *c
+	cc.options = make([]RequestOption, 0, len(c.options) + len(options))
+	cc.options = append(cc.options, c.options...)
+	cc.options = append(cc.options, options...)
+	return &cc
+}
+
+// Create `DefaultClient` with Request Options
+//
+// # Arguments
+// * `options`: `...RequestOption` - Request Options like `WithHeader()`
+//
+// # Returns
+// * `*Client` - Client with Request Options
+func With(options ...RequestOption) *Client {
+	return DefaultClient.With(options...)
+}
+
+// newRequestConfig applies options.
+func newRequestConfig(options []RequestOption) (*requestConfig, error) {
+	cfg := &requestConfig{ header: http.Header{}, query: url.Values{} }
+	for _, o := range options {
+		o(cfg)
+	}
+
+	if cfg.err != nil {
+		return nil, cfg.err
+	}
+	return cfg, nil
+}
+
+// apply sets headers and auth to req.
+func (cfg *requestConfig) apply(req *http.Request) {
+	for k, v := range cfg.header {
+		req.Header[k] = v
+	}
+	if cfg.auth != nil {
+		cfg.auth(req)
+	}
+}
+
+// url appends query parameters to rawURL.
+// Existing query is kept as it is.
+func (cfg *requestConfig) url(rawURL string) (string, error) {
+	if len(cfg.query) == 0 {
+		return rawURL, nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	if u.RawQuery == "" {
+		u.RawQuery = cfg.query.Encode()
+	} else {
+		u.RawQuery += "&" + cfg.query.Encode()
+	}
+
+	return u.String(), nil
+}
+
+
+// Request Option to set Header
+//
+// # Arguments
+// * `key`: `string` - Header Key
+// * `values`: `...string` - Header Values. Previous values are replaced.
+//
+// # Returns
+// * `RequestOption` - Request Option
+func WithHeader(key string, values ...string) RequestOption {
+	return func(cfg *requestConfig){
+		cfg.header[http.CanonicalHeaderKey(key)] = values
+	}
+}
+
+// Request Option to set Headers
+//
+// # Arguments
+// * `header`: `http.Header` - Headers. Previous values of the same keys are replaced.
+//
+// # Returns
+// * `RequestOption` - Request Option
+func WithHeaders(header http.Header) RequestOption {
+	return func(cfg *requestConfig){
+		for k, v := range header {
+			cfg.header[http.CanonicalHeaderKey(k)] = v
+		}
+	}
+}
+
+// Request Option to add URL Query Parameters
+//
+// `query` can be `url.Values`, `map[string]string`, `map[string][]string`,
+// `map[string]any`, or struct (and pointer to it).
+// Struct fields can be annotated like `query:"name,omitempty"`,
+// and fields with `query:"-"` are ignored.
+// Slice values are added as multiple values.
+//
+// # Arguments
+// * `query`: `any` - Query Parameters
+//
+// # Returns
+// * `RequestOption` - Request Option
+func WithQuery(query any) RequestOption {
+	return func(cfg *requestConfig){
+		if err := addQuery(cfg.query, query); err != nil {
+			cfg.err = err
+		}
+	}
+}
+
+// Request Option for Bearer Authorization
+//
+// # Arguments
+// * `token`: `string` - Bearer Token
+//
+// # Returns
+// * `RequestOption` - Request Option
+func WithBearerToken(token string) RequestOption {
+	return func(cfg *requestConfig){
+		cfg.auth = func(req *http.Request){
+			req.Header.Set("Authorization", "Bearer " + token)
+		}
+	}
+}
+
+// Request Option for Basic Authorization
+//
+// # Arguments
+// * `username`: `string` - User Name
+// * `password`: `string` - Password
+//
+// # Returns
+// * `RequestOption` - Request Option
+func WithBasicAuth(username, password string) RequestOption {
+	return func(cfg *requestConfig){
+		cfg.auth = func(req *http.Request){
+			req.SetBasicAuth(username, password)
+		}
+	}
+}
+
+// Request Option for Timeout
+//
+// Timeout covers all the retries and reading response body.
+//
+// # Arguments
+// * `timeout`: `time.Duration` - Timeout
+//
+// # Returns
+// * `RequestOption` - Request Option
+func WithTimeout(timeout time.Duration) RequestOption {
+	return func(cfg *requestConfig){
+		cfg.timeout = timeout
+	}
+}
+
+// Request Option to retain raw response body in `Response.RawBody`
+//
+// It is useful for debugging when decoding fails.
+//
+// # Returns
+// * `RequestOption` - Request Option
+func WithRawBody() RequestOption {
+	return func(cfg *requestConfig){
+		cfg.rawBody = true
+	}
+}
+
+// addQuery adds query to q.
+func addQuery(q url.Values, query any) error {
+	switch query := query.(type) {
+	case nil:
+		return nil
+	case url.Values:
+		for k, v := range query {
+			q[k] = append(q[k], v...)
+		}
+		return nil
+	case map[string]string:
+		for k, v := range query {
+			q.Add(k, v)
+		}
+		return nil
+	case map[string][]string:
+		for k, v := range query {
+			q[k] = append(q[k], v...)
+		}
+		return nil
+	}
+
+	v := reflect.ValueOf(query)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("%w: %T", ErrUnsupportedQuery, query)
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			addQueryValue(q, iter.Key().String(), iter.Value(), false)
+		}
+		return nil
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+
+			name := f.Name
+			omitempty := false
+			if tag, ok := f.Tag.Lookup("query"); ok {
+				if tag == "-" {
+					continue
+				}
+				n, opts, _ := strings.Cut(tag, ",")
+				if n != "" {
+					name = n
+				}
+				omitempty = (opts == "omitempty")
+			}
+
+			addQueryValue(q, name, v.Field(i), omitempty)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: %T", ErrUnsupportedQuery, query)
+	}
+}
+
+// addQueryValue adds v as string.
+// Nil pointer is skipped, and slice is added as multiple values.
+func addQueryValue(q url.Values, name string, v reflect.Value, omitempty bool) {
+	for (v.Kind() == reflect.Pointer) || (v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	if omitempty && v.IsZero() {
+		return
+	}
+
+	if ((v.Kind() == reflect.Slice) || (v.Kind() == reflect.Array)) &&
+		(v.Type().Elem().Kind() != reflect.Uint8) {
+		for i := 0; i < v.Len(); i++ {
+			addQueryValue(q, name, v.Index(i), false)
+		}
+		return
+	}
+
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		q.Add(name, s.String())
+		return
+	}
+	if b, ok := v.Interface().([]byte); ok {
+		q.Add(name, string(b))
+		return
+	}
+	q.Add(name, fmt.Sprint(v.Interface()))
+}
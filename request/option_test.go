@@ -0,0 +1,197 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ymd-h/go/request/json"
+)
+
+type (
+	// blockingClient blocks until request context is done.
+	blockingClient struct {}
+)
+
+func (_ blockingClient) Do(req *http.Request) (*http.Response, error) {
+	<- req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+func TestRequestOptions(t *testing.T){
+	f := &fakeClient{ responses: []fakeResponse{ { code: http.StatusOK } } }
+	c := NewClient(f, json.Encoder{}, json.Decoder{})
+
+	type Q struct {
+		Page int `query:"page"`
+		Tags []string `query:"tag"`
+		Empty string `query:"empty,omitempty"`
+		Skip string `query:"-"`
+		Ptr *int `query:"ptr"`
+		Name string
+	}
+
+	_, err := c.With(
+		WithHeader("x-custom", "v1", "v2"),
+		WithHeaders(http.Header{ "Content-Type": []string{"text/plain"} }),
+		WithQuery(&Q{ Page: 2, Tags: []string{"a", "b"}, Skip: "s", Name: "n" }),
+		WithQuery(map[string]string{ "m": "1" }),
+		WithBearerToken("token"),
+	).Post("http://example.com/path?x=1", "a", nil)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	req := f.requests[0]
+	if v := req.Header.Values("X-Custom"); (len(v) != 2) || (v[0] != "v1") || (v[1] != "v2") {
+		t.Errorf("Fail: %v\n", v)
+		return
+	}
+	if v := req.Header.Get("Content-Type"); v != "text/plain" {
+		t.Errorf("Fail: %s\n", v)
+		return
+	}
+	if v := req.Header.Get("Authorization"); v != "Bearer token" {
+		t.Errorf("Fail: %s\n", v)
+		return
+	}
+
+	want := url.Values{
+		"x": []string{"1"},
+		"page": []string{"2"},
+		"tag": []string{"a", "b"},
+		"Name": []string{"n"},
+		"m": []string{"1"},
+	}
+	if q := req.URL.Query(); q.Encode() != want.Encode() {
+		t.Errorf("Fail: %s (want %s)\n", q.Encode(), want.Encode())
+		return
+	}
+
+	_, err = c.With(WithBasicAuth("user", "pass")).Get("http://example.com/", nil)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if u, p, ok := f.requests[1].BasicAuth(); !ok || (u != "user") || (p != "pass") {
+		t.Errorf("Fail: %s %s %v\n", u, p, ok)
+		return
+	}
+
+	_, err = c.With(WithQuery(1)).Get("http://example.com/", nil)
+	if !errors.Is(err, ErrUnsupportedQuery) {
+		t.Errorf("Must be ErrUnsupportedQuery: %v\n", err)
+		return
+	}
+
+	// Existing query is neither re-encoded nor reordered.
+	_, err = c.With(WithQuery(map[string]string{ "m": "1" })).Get("http://example.com/?z=2&x=%2f&y", nil)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if q := f.requests[len(f.requests)-1].URL.RawQuery; q != "z=2&x=%2f&y&m=1" {
+		t.Errorf("Fail: %s\n", q)
+		return
+	}
+}
+
+func TestClientWith(t *testing.T){
+	f := &fakeClient{ responses: []fakeResponse{ { code: http.StatusOK } } }
+	c := NewClient(f, json.Encoder{}, json.Decoder{})
+
+	// Existing signatures are kept.
+	var get func(string, any) (*Response, error) = c.Get
+	var post func(string, any, any) (*Response, error) = c.Post
+
+	a := c.With(WithHeader("X-A", "a"))
+	b := a.With(WithHeader("X-B", "b"))
+
+	for _, do := range []func() error{
+		func() error { _, err := get("http://example.com/", nil); return err },
+		func() error { _, err := post("http://example.com/", 1, nil); return err },
+		func() error { _, err := a.Get("http://example.com/", nil); return err },
+		func() error { _, err := b.Get("http://example.com/", nil); return err },
+	}{
+		if err := do(); err != nil {
+			t.Errorf("Fail: %v\n", err)
+			return
+		}
+	}
+
+	for i, want := range [][2]string{ {"", ""}, {"", ""}, {"a", ""}, {"a", "b"} } {
+		h := f.requests[i].Header
+		if (h.Get("X-A") != want[0]) || (h.Get("X-B") != want[1]) {
+			t.Errorf("Fail %d: %v\n", i, h)
+			return
+		}
+	}
+}
+
+func TestRequestTimeout(t *testing.T){
+	c := NewClient(blockingClient{}, json.Encoder{}, json.Decoder{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.With(WithTimeout(10 * time.Millisecond)).GetWithContext(ctx, "http://example.com/", nil)
+	if err == nil {
+		t.Errorf("Must Fail\n")
+		return
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Fail: %v\n", d)
+		return
+	}
+}
+
+func TestResponseMetadata(t *testing.T){
+	f := &fakeClient{
+		responses: []fakeResponse{
+			{
+				code: http.StatusOK,
+				header: http.Header{
+					"Etag": []string{`"abc"`},
+					"Link": []string{`<http://example.com/?page=2>; rel="next"`},
+				},
+				body: `{"a": 1}`,
+			},
+			{ code: http.StatusOK, body: `invalid` },
+		},
+	}
+	c := NewClient(f, json.Encoder{}, json.Decoder{})
+
+	var v map[string]int
+	res, err := c.Get("http://example.com/", &v)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if res.Header.Get("ETag") != `"abc"` {
+		t.Errorf("Fail: %v\n", res.Header)
+		return
+	}
+	if res.Header.Get("Link") == "" {
+		t.Errorf("Fail: %v\n", res.Header)
+		return
+	}
+	if res.RawBody != nil {
+		t.Errorf("RawBody must be nil: %s\n", string(res.RawBody))
+		return
+	}
+
+	res, err = c.With(WithRawBody()).Get("http://example.com/", &v)
+	if err == nil {
+		t.Errorf("Must Fail\n")
+		return
+	}
+	if (res == nil) || (string(res.RawBody) != "invalid") {
+		t.Errorf("Fail: %v\n", res)
+		return
+	}
+}
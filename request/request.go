@@ -2,11 +2,15 @@
 package request
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
+	"github.com/ymd-h/go/encoding"
 	"github.com/ymd-h/go/request/json"
 )
 
@@ -27,21 +31,71 @@ type (
 		Decode(io.Reader, any) error
 	}
 
+	// Interface for Request Body Encoder with Content-Encoding
+	// like `encoding.WrappedEncoder`
+	IContentEncoder interface {
+		ContentEncoding() string
+	}
+
+	// Interface for Response Body Decoder with Content-Encoding
+	// like `encoding.WrappedDecoder`
+	//
+	// `ContentEncoding()` is sent as `Accept-Encoding` header,
+	// and response body is decoded by `Content-Encoding` header of the response.
+	IContentDecoder interface {
+		ContentEncoding() string
+		Unwrap() encoding.ICodecDecoder
+	}
+
 	// Client class
 	Client struct {
 		client IHttpClient
 		encoder IBodyEncoder
 		decoder IBodyDecoder
+		retry RetryPolicy
+		sleep func(context.Context, time.Duration) error
+		middlewares []Middleware
+		encodeMiddlewares []EncodeMiddleware
+		decodeMiddlewares []DecodeMiddleware
+		options []RequestOption
+		streaming bool
+		doFunc DoFunc
+		encodeFunc EncodeFunc
+		decodeFunc DecodeFunc
+	}
+
+	// Request Body Encoder wrapped with Content-Encoding
+	ContentEncoder struct {
+		encoding.WrappedEncoder
+		contentType string
 	}
 
 	// Response class
 	Response struct {
 		StatusCode int
 		Body any
+
+		// Response Headers
+		Header http.Header
+
+		// Protocol like "HTTP/1.1"
+		Proto string
+
+		// Content-Length. `-1` means unknown.
+		ContentLength int64
+
+		// Elapsed time until response headers are received, including retries
+		Duration time.Duration
+
+		// Raw response body as received.
+		// Only set when `WithRawBody()` is passed.
+		RawBody []byte
 	}
 )
 
 var (
+	ErrNoEncoder = errors.New("No request body encoder")
+
 	// Default Client
 	DefaultClient = NewClient(
 		http.DefaultClient,
@@ -55,6 +109,9 @@ var (
 //
 // # Arguments
 // * `client`: `IClient` - `*http.Client`
+// * `encoder`: `IBodyEncoder` - Request Body Encoder
+// * `decoder`: `IBodyDecoder` - Response Body Decoder
+// * `options`: `...ClientOption` - Client Options like `WithRetry()`
 //
 // # Returns
 // * `*Client` - Created Client
@@ -62,12 +119,77 @@ func NewClient(
 	client IHttpClient,
 	encoder IBodyEncoder,
 	decoder IBodyDecoder,
+	options ...ClientOption,
 ) *Client {
-	return &Client{client: client, encoder: encoder, decoder: decoder}
+	c := &Client{client: client, encoder: encoder, decoder: decoder, sleep: sleep}
+	for _, o := range options {
+		o(c)
+	}
+	c.buildChain()
+	return c
+}
+
+
+// Client Option to stream request body
+//
+// Request body is written by `EncodeTo()` of the encoder
+// while it is sent, instead of being encoded into memory beforehand.
+// Since streamed body has neither `Content-Length` nor `GetBody`,
+// 307 and 308 redirects are not followed.
+//
+// # Returns
+// * `ClientOption` - Client Option
+func WithStreamingBody() ClientOption {
+	return func(c *Client){
+		c.streaming = true
+	}
+}
+
+// Wrap Request Body Encoder with Content-Encoding
+//
+// # Arguments
+// * `enc`: `IBodyEncoder` - Request Body Encoder
+// * `codings`: `...encoding.ContentCoding` - Content Codings applied in order
+//
+// # Returns
+// * `ContentEncoder` - Wrapped Encoder
+func WrapEncoder(enc IBodyEncoder, codings ...encoding.ContentCoding) ContentEncoder {
+	return ContentEncoder{
+		WrappedEncoder: encoding.WrapEncoder(enc, codings...),
+		contentType: enc.ContentType(),
+	}
+}
+
+func (e ContentEncoder) ContentType() string {
+	return e.contentType
 }
 
 
 func (c *Client) newHttpReqest(
+	ctx context.Context,
+	cfg *requestConfig,
+	method, url string,
+	request any,
+) (*http.Request, error) {
+	req, err := c.newHttpRequestBody(ctx, method, url, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if cd, ok := c.decoder.(IContentDecoder); ok {
+		if ae := cd.ContentEncoding(); ae != "" {
+			req.Header.Set("Accept-Encoding", ae)
+		}
+	}
+	if ctd, ok := c.decoder.(IContentTypeDecoder); ok {
+		req.Header.Set("Accept", ctd.Accept())
+	}
+
+	cfg.apply(req)
+	return req, nil
+}
+
+func (c *Client) newHttpRequestBody(
 	ctx context.Context,
 	method, url string,
 	request any,
@@ -76,20 +198,89 @@ func (c *Client) newHttpReqest(
 		return http.NewRequestWithContext(ctx, method, url, nil)
 	}
 
-	body, err := c.encoder.Encode(request)
+	body, err := c.encodeFunc(request)
 	if err != nil {
 		return nil, err
 	}
 
+	// http.NewRequestWithContext sets ContentLength and GetBody,
+	// which is required to follow 307 and 308 redirects,
+	// for *bytes.Buffer, *bytes.Reader and *strings.Reader.
+	// Other readers are streamed without GetBody, since they cannot be replayed.
 	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
+		if c, ok := body.(io.Closer); ok {
+			c.Close()
+		}
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", c.encoder.ContentType())
+	if p, ok := body.(*encoding.PooledReader); ok {
+		req.ContentLength = int64(p.Len())
+		if req.ContentLength == 0 {
+			p.Close()
+			req.Body = http.NoBody
+		}
+	}
+
+	if c.encoder != nil {
+		req.Header.Set("Content-Type", c.encoder.ContentType())
+	}
+	if ce, ok := c.encoder.(IContentEncoder); ok {
+		if e := ce.ContentEncoding(); e != "" {
+			req.Header.Set("Content-Encoding", e)
+		}
+	}
 	return req, nil
 }
 
+// encodeBody encodes request with encoder.
+// With `WithStreamingBody()`, encoder implementing `encoding.ICodecEncoderTo`
+// writes to `io.Pipe`, so that the body is streamed without buffering.
+func (c *Client) encodeBody(request any) (io.Reader, error) {
+	if c.encoder == nil {
+		return nil, ErrNoEncoder
+	}
+
+	e, ok := c.encoder.(encoding.ICodecEncoderTo)
+	if !c.streaming || !ok {
+		return c.encoder.Encode(request)
+	}
+
+	r, w := io.Pipe()
+	go func(){
+		w.CloseWithError(e.EncodeTo(w, request))
+	}()
+	return r, nil
+}
+
+// decodeBody decodes response body to response.
+// If decoder is `IContentTypeDecoder`, decoder is chosen by `Content-Type`.
+// If decoder is `IContentDecoder`, `Content-Encoding` is removed beforehand.
+func (c *Client) decodeBody(res *http.Response, response any) error {
+	decoder := IBodyDecoder(c.decoder)
+	if ctd, ok := decoder.(IContentTypeDecoder); ok {
+		d, err := ctd.DecoderFor(res.Header.Get("Content-Type"))
+		if err != nil {
+			return err
+		}
+		decoder = d
+	}
+
+	cd, ok := decoder.(IContentDecoder)
+	if !ok {
+		return decoder.Decode(res.Body, response)
+	}
+
+	body, err := encoding.DecodeContent(res.Body, res.Header.Get("Content-Encoding"))
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	return cd.Unwrap().Decode(body, response)
+}
+
 
 // FetchWithContext
 //
@@ -100,9 +291,11 @@ func (c *Client) newHttpReqest(
 // * `request`: `any` - Request Body Struct annotated with JSON or `nil`
 // * `response`: `any` - Pointer to Response Body Struct annotated with JSON or `ResponseDispatcher`
 //
+// Request Options set by `With()` are applied.
+//
 // # Returns
 // * `*Response` - Response
-// * `error` - Error
+// * `error` - Error. `*HTTPError` when dispatched to rule marked by `AsError()`
 func (c *Client) FetchWithContext(
 	ctx context.Context,
 	method, url string,
@@ -110,32 +303,66 @@ func (c *Client) FetchWithContext(
 ) (*Response, error) {
 	s := fmt.Sprintf("%s at %s", method, url)
 
-	req, err := c.newHttpReqest(ctx, method, url, request)
+	cfg, err := newRequestConfig(c.options)
 	if err != nil {
-		return nil, fmt.Errorf("Fail to Create New Request for %s: %w", s, err)
+		return nil, fmt.Errorf("Fail to Apply Options for %s: %w", s, err)
 	}
 
-	res, err := c.client.Do(req)
+	url, err = cfg.url(url)
 	if err != nil {
-		return nil, fmt.Errorf("Fail to %s: %v", s, err)
+		return nil, fmt.Errorf("Fail to Add Query for %s: %w", s, err)
+	}
+
+	if cfg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	res, err := c.do(ctx, cfg, method, url, request)
+	if err != nil {
+		return nil, err
 	}
 	defer res.Body.Close()
 
-	ret := &Response{StatusCode: res.StatusCode, Body: nil}
+	ret := &Response{
+		StatusCode: res.StatusCode,
+		Body: nil,
+		Header: res.Header,
+		Proto: res.Proto,
+		ContentLength: res.ContentLength,
+		Duration: time.Since(start),
+	}
+
+	if cfg.rawBody {
+		ret.RawBody, err = io.ReadAll(res.Body)
+		if err != nil {
+			return ret, fmt.Errorf(
+				"Fail to Read Response (StatusCode: %d) for %s: %w",
+				ret.StatusCode, s, err)
+		}
+		res.Body = io.NopCloser(bytes.NewReader(ret.RawBody))
+	}
+
 	if response == nil {
 		return ret, nil
 	}
 
+	isError := false
 	if rd, ok := response.(*ResponseDispatcher); ok {
-		response, err = rd.Dispatch(ret.StatusCode)
+		ct := res.Header.Get("Content-Type")
+		rule, err := rd.Lookup(ret.StatusCode, ct)
 		if err != nil {
 			return ret, fmt.Errorf(
-				"Fail to Dispatch Response (StatusCode: %d) for %s: %w",
-				ret.StatusCode, s, err)
+				"Fail to Dispatch Response (StatusCode: %d, Content-Type: %s) for %s: %w",
+				ret.StatusCode, ct, s, err)
 		}
+		response = rule.Response()
+		isError = isErrorRule(rule)
 	}
 
-	err = c.decoder.Decode(res.Body, response)
+	err = c.decodeFunc(res, response)
 	if err != nil {
 		return ret, fmt.Errorf(
 			"Fail to Decode Response (StatucCode: %d) for %s: %w",
@@ -143,6 +370,15 @@ func (c *Client) FetchWithContext(
 	}
 
 	ret.Body = response
+	if isError {
+		return ret, &HTTPError{
+			Method: method,
+			URL: url,
+			StatusCode: ret.StatusCode,
+			Header: ret.Header,
+			Body: response,
+		}
+	}
 	return ret, nil
 }
 
@@ -3,19 +3,26 @@ package request
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"testing"
 	"time"
 
 	"github.com/ymd-h/go/async"
+	"github.com/ymd-h/go/encoding"
 	"github.com/ymd-h/go/slices"
 	"github.com/ymd-h/go/request/gob"
+	rjson "github.com/ymd-h/go/request/json"
 	"github.com/ymd-h/go/request/xml"
 )
 
 type (
+	// encoderFunc is IBodyEncoder made from function.
+	encoderFunc func(any) (io.Reader, error)
+
 	A struct {
 		A1 string `json:"a1" xml:"a1"`
 		A2 int `json:"a2" xml:"a2"`
@@ -27,6 +34,14 @@ type (
 	}
 )
 
+func (f encoderFunc) Encode(v any) (io.Reader, error) {
+	return f(v)
+}
+
+func (_ encoderFunc) ContentType() string {
+	return "text/plain"
+}
+
 
 func TestRequest(t *testing.T){
 	http.HandleFunc("/A", func(w http.ResponseWriter, req *http.Request){
@@ -54,6 +69,26 @@ func TestRequest(t *testing.T){
 		}
 		w.Write(b)
 	})
+	http.HandleFunc("/echo-encoding", func(w http.ResponseWriter, req *http.Request){
+		if req.Header.Get("Accept-Encoding") != "gzip" {
+			t.Error("Fail /echo-encoding: Accept-Encoding")
+			return
+		}
+
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Error("Fail /echo-encoding")
+			return
+		}
+		w.Header().Set("Content-Encoding", req.Header.Get("Content-Encoding"))
+		w.Write(b)
+	})
+	http.HandleFunc("/redirect", func(w http.ResponseWriter, req *http.Request){
+		http.Redirect(w, req, "/echo", http.StatusTemporaryRedirect)
+	})
+	http.HandleFunc("/length", func(w http.ResponseWriter, req *http.Request){
+		json.NewEncoder(w).Encode(req.ContentLength)
+	})
 	http.HandleFunc("/ctx", func(w http.ResponseWriter, req *http.Request){
 		<- req.Context().Done()
 		return
@@ -70,7 +105,13 @@ func TestRequest(t *testing.T){
 		return
 	})
 
-	go http.ListenAndServe("localhost:8888", nil)
+	// Listen before serving, so that requests never reach before it.
+	l, err := net.Listen("tcp", "localhost:8888")
+	if err != nil {
+		t.Errorf("Fail to Listen: %v\n", err)
+		return
+	}
+	go http.Serve(l, nil)
 
 	url := func(path string) string {
 		return fmt.Sprintf("http://localhost:8888/%s", path)
@@ -87,6 +128,14 @@ func TestRequest(t *testing.T){
 			t.Errorf("Fail StatusCode: %d\n", resp.StatusCode)
 			return
 		}
+		if (resp.Proto != "HTTP/1.1") || (resp.Duration <= 0) {
+			t.Errorf("Fail: %s %v\n", resp.Proto, resp.Duration)
+			return
+		}
+		if resp.Header.Get("Content-Type") == "" {
+			t.Errorf("Fail: %v\n", resp.Header)
+			return
+		}
 	})
 
 	t.Run("GET-partial", func(*testing.T){
@@ -235,8 +284,66 @@ func TestRequest(t *testing.T){
 		}
 	})
 
+	t.Run("POST-gzip", func(*testing.T){
+		c := NewClient(
+			http.DefaultClient,
+			WrapEncoder(rjson.Encoder{}, encoding.Gzip),
+			encoding.WrapDecoder(rjson.Decoder{}, encoding.Gzip),
+		)
+
+		a := A{
+			A1: "gzip",
+			A2: 1,
+			A3: B{
+				B1: true,
+				B2: []byte("compressed"),
+			},
+		}
+		var aa A
+
+		_, err := c.Post(url("echo-encoding"), &a, &aa)
+		if err != nil {
+			t.Errorf("Fail: %v\n", err)
+			return
+		}
+
+		if (a.A1 != aa.A1) || (a.A2 != aa.A2) || (a.A3.B1 != aa.A3.B1) {
+			t.Errorf("Fail: %v != %v\n", a, aa)
+			return
+		}
+	})
+
+	t.Run("POST-length", func(*testing.T){
+		var n int64
+		_, err := Post(url("length"), "abc", &n)
+		if err != nil {
+			t.Errorf("Fail: %v\n", err)
+			return
+		}
+
+		// "abc" + "\n"
+		if n != 6 {
+			t.Errorf("Fail: %d\n", n)
+			return
+		}
+	})
+
+	t.Run("POST-redirect", func(*testing.T){
+		var s string
+		resp, err := Post(url("redirect"), "abc", &s)
+		if err != nil {
+			t.Errorf("Fail: %v\n", err)
+			return
+		}
+		if (resp.StatusCode != http.StatusOK) || (s != "abc") {
+			t.Errorf("Fail: %d %s\n", resp.StatusCode, s)
+			return
+		}
+	})
+
 	t.Run("ctx", func(*testing.T){
 		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
 
 		job := async.Run(
 			async.WrapErrorFunc(
@@ -317,3 +424,97 @@ func TestRequest(t *testing.T){
 		}
 	})
 }
+
+func TestRequestBodyStream(t *testing.T){
+	called := make(chan struct{})
+
+	// Rest of body is written only after Do is called.
+	enc := encoderFunc(func(v any) (io.Reader, error) {
+		r, w := io.Pipe()
+		go func(){
+			w.Write([]byte("abc"))
+			select {
+			case <- called:
+			case <- time.After(time.Second):
+				w.CloseWithError(errors.New("Body is buffered before Do"))
+				return
+			}
+			w.Write([]byte("def"))
+			w.Close()
+		}()
+		return r, nil
+	})
+
+	f := &fakeClient{ responses: []fakeResponse{ { code: http.StatusOK } } }
+	c := NewClient(f, enc, rjson.Decoder{}, WithMiddleware(func(next DoFunc) DoFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			close(called)
+			return next(req)
+		}
+	}))
+
+	if _, err := c.Post("http://example.com/", "", nil); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if f.bodies[0] != "abcdef" {
+		t.Errorf("Fail: %s\n", f.bodies[0])
+		return
+	}
+	if f.requests[0].GetBody != nil {
+		t.Errorf("Fail: non-replayable body must not have GetBody\n")
+		return
+	}
+}
+
+func TestRequestBodyPooled(t *testing.T){
+	enc := encoderFunc(func(v any) (io.Reader, error) {
+		return encoding.EncodePooled(json.NewEncoder, v)
+	})
+
+	f := &fakeClient{ responses: []fakeResponse{ { code: http.StatusOK } } }
+	c := NewClient(f, enc, rjson.Decoder{})
+
+	if _, err := c.Post("http://example.com/", "abc", nil); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if (f.bodies[0] != "\"abc\"\n") || (f.requests[0].ContentLength != 6) {
+		t.Errorf("Fail: %q %d\n", f.bodies[0], f.requests[0].ContentLength)
+		return
+	}
+	if f.requests[0].GetBody != nil {
+		t.Errorf("Fail: PooledReader must not have GetBody\n")
+		return
+	}
+}
+
+func TestStreamingBody(t *testing.T){
+	for _, tc := range []struct{
+		name string
+		options []ClientOption
+		length int64
+	}{
+		{ name: "buffered", length: 6 },
+		{ name: "streaming", options: []ClientOption{ WithStreamingBody() }, length: 0 },
+	}{
+		tc := tc
+		t.Run(tc.name, func(t *testing.T){
+			f := &fakeClient{ responses: []fakeResponse{ { code: http.StatusOK } } }
+			c := NewClient(f, rjson.Encoder{}, rjson.Decoder{}, tc.options...)
+
+			if _, err := c.Post("http://example.com/", "abc", nil); err != nil {
+				t.Errorf("Fail: %v\n", err)
+				return
+			}
+			if (f.bodies[0] != "\"abc\"\n") || (f.requests[0].ContentLength != tc.length) {
+				t.Errorf("Fail: %q %d\n", f.bodies[0], f.requests[0].ContentLength)
+				return
+			}
+			if (f.requests[0].GetBody != nil) != (tc.length > 0) {
+				t.Errorf("Fail: GetBody\n")
+				return
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type (
+	// Retry Policy
+	//
+	// Network errors and `StatusCodes` are retried
+	// with exponential backoff and jitter.
+	// When response has `Retry-After` header, it is honored.
+	// If `Retry-After` exceeds `MaxBackoff`, retry is given up
+	// and the response is returned.
+	// Only idempotent methods (and requests with `Idempotency-Key` header)
+	// are retried unless `RetryNonIdempotent` is `true`.
+	RetryPolicy struct {
+		// Maximum number of attempts including the first one.
+		// `1` or less means no retry.
+		MaxAttempts int
+
+		// Backoff before the first retry
+		InitialBackoff time.Duration
+
+		// Upper bound of backoff and `Retry-After`. `0` means no limit.
+		// Longer `Retry-After` stops retry.
+		MaxBackoff time.Duration
+
+		// Backoff multiplier for each retry. `0` means `2`.
+		Multiplier float64
+
+		// Jitter ratio in [0, 1].
+		// Backoff is randomized in [(1 - Jitter) * backoff, backoff].
+		Jitter float64
+
+		// Status codes to be retried. `nil` means `DefaultRetryStatusCodes`.
+		StatusCodes []int
+
+		// Retry non-idempotent methods like POST, too.
+		RetryNonIdempotent bool
+	}
+
+	// Client Option
+	ClientOption func(*Client)
+)
+
+var (
+	// Default status codes to be retried
+	DefaultRetryStatusCodes = []int{
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	}
+)
+
+
+// Create default RetryPolicy
+//
+// 3 attempts, 100ms initial backoff (x2), 10s max backoff, and 0.5 jitter.
+//
+// # Returns
+// * `RetryPolicy` - Default Retry Policy
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff: 10 * time.Second,
+		Multiplier: 2,
+		Jitter: 0.5,
+	}
+}
+
+// Client Option for Retry
+//
+// # Arguments
+// * `policy`: `RetryPolicy` - Retry Policy
+//
+// # Returns
+// * `ClientOption` - Client Option
+func WithRetry(policy RetryPolicy) ClientOption {
+	return func(c *Client){
+		c.retry = policy
+	}
+}
+
+// isIdempotent returns true if req can be retried safely.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return req.Header.Get("Idempotency-Key") != ""
+	}
+}
+
+// shouldRetry returns true if the attempt-th try should be retried.
+func (p RetryPolicy) shouldRetry(
+	ctx context.Context,
+	req *http.Request,
+	res *http.Response,
+	err error,
+	attempt int,
+) bool {
+	if attempt >= p.MaxAttempts {
+		return false
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+	if !p.RetryNonIdempotent && !isIdempotent(req) {
+		return false
+	}
+
+	if err != nil {
+		return true
+	}
+
+	codes := p.StatusCodes
+	if codes == nil {
+		codes = DefaultRetryStatusCodes
+	}
+	for _, c := range codes {
+		if res.StatusCode == c {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns wait duration after the attempt-th try.
+// If `Retry-After` exceeds `MaxBackoff`, false is returned.
+func (p RetryPolicy) backoff(res *http.Response, attempt int) (time.Duration, bool) {
+	m := p.Multiplier
+	if m == 0 {
+		m = 2
+	}
+
+	b := float64(p.InitialBackoff) * math.Pow(m, float64(attempt - 1))
+	if (p.MaxBackoff > 0) && (b > float64(p.MaxBackoff)) {
+		b = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		b -= b * p.Jitter * rand.Float64()
+	}
+	d := time.Duration(b)
+
+	if res != nil {
+		if ra, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+			if (p.MaxBackoff > 0) && (ra > p.MaxBackoff) {
+				return 0, false
+			}
+			if ra > d {
+				d = ra
+			}
+		}
+	}
+
+	return d, true
+}
+
+// parseRetryAfter parses Retry-After header, which is seconds or HTTP date.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+
+	if s, err := strconv.Atoi(v); err == nil {
+		if s < 0 {
+			return 0, false
+		}
+		return time.Duration(s) * time.Second, true
+	}
+
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	return time.Until(t), true
+}
+
+// sleep waits d or ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <- timer.C:
+		return nil
+	case <- ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// discard drains and closes response body, so that connection can be reused.
+func discard(res *http.Response) {
+	io.CopyN(io.Discard, res.Body, 4096)
+	res.Body.Close()
+}
+
+// do sends request with retry.
+// Request body is encoded for each attempt.
+func (c *Client) do(
+	ctx context.Context,
+	cfg *requestConfig,
+	method, url string,
+	request any,
+) (*http.Response, error) {
+	s := fmt.Sprintf("%s at %s", method, url)
+
+	for attempt := 1; ; attempt++ {
+		req, err := c.newHttpReqest(ctx, cfg, method, url, request)
+		if err != nil {
+			return nil, fmt.Errorf("Fail to Create New Request for %s: %w", s, err)
+		}
+
+		res, err := c.doFunc(req)
+		if (res != nil) && (err != nil) {
+			// Response with error must be closed, too.
+			discard(res)
+			res = nil
+		}
+
+		if !c.retry.shouldRetry(ctx, req, res, err, attempt) {
+			if err != nil {
+				return nil, fmt.Errorf("Fail to %s: %v", s, err)
+			}
+			return res, nil
+		}
+
+		wait, ok := c.retry.backoff(res, attempt)
+		if !ok {
+			// Server doesn't allow retry within MaxBackoff.
+			return res, nil
+		}
+		if res != nil {
+			discard(res)
+		}
+
+		if serr := c.sleep(ctx, wait); serr != nil {
+			if err == nil {
+				err = serr
+			}
+			return nil, fmt.Errorf("Fail to %s after %d attempts: %v", s, attempt, err)
+		}
+	}
+}
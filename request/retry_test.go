@@ -0,0 +1,312 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ymd-h/go/request/json"
+)
+
+type (
+	// fakeClient returns responses in order, and records requests.
+	fakeClient struct {
+		responses []fakeResponse
+		requests []*http.Request
+		bodies []string
+	}
+
+	fakeResponse struct {
+		code int
+		header http.Header
+		body string
+		err error
+	}
+)
+
+// closeCounter counts Close calls.
+type closeCounter struct {
+	io.Reader
+	n *int
+}
+
+func (c closeCounter) Close() error {
+	*c.n += 1
+	return nil
+}
+
+func (f *fakeClient) Do(req *http.Request) (*http.Response, error) {
+	f.requests = append(f.requests, req)
+
+	body := ""
+	if req.Body != nil {
+		b, _ := io.ReadAll(req.Body)
+		body = string(b)
+	}
+	f.bodies = append(f.bodies, body)
+
+	r := f.responses[0]
+	if len(f.responses) > 1 {
+		f.responses = f.responses[1:]
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	header := r.header
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		StatusCode: r.code,
+		Proto: "HTTP/1.1",
+		Header: header,
+		ContentLength: int64(len(r.body)),
+		Body: io.NopCloser(strings.NewReader(r.body)),
+		Request: req,
+	}, nil
+}
+
+func newRetryClient(f *fakeClient, p RetryPolicy) (*Client, *[]time.Duration) {
+	waits := make([]time.Duration, 0)
+
+	c := NewClient(f, json.Encoder{}, json.Decoder{}, WithRetry(p))
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	return c, &waits
+}
+
+func TestRetry(t *testing.T){
+	f := &fakeClient{
+		responses: []fakeResponse{
+			{ err: errors.New("network error") },
+			{ code: http.StatusServiceUnavailable },
+			{ code: http.StatusOK, body: `"ok"` },
+		},
+	}
+	c, waits := newRetryClient(f, RetryPolicy{
+		MaxAttempts: 3,
+		InitialBackoff: time.Second,
+		Multiplier: 3,
+	})
+
+	var s string
+	res, err := c.Put("http://example.com/", "body", &s)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if (res.StatusCode != http.StatusOK) || (s != "ok") {
+		t.Errorf("Fail: %d %s\n", res.StatusCode, s)
+		return
+	}
+
+	if len(f.requests) != 3 {
+		t.Errorf("Fail: %d\n", len(f.requests))
+		return
+	}
+	for _, b := range f.bodies {
+		// Body is re-encoded for each attempt.
+		if b != "\"body\"\n" {
+			t.Errorf("Fail: %q\n", b)
+			return
+		}
+	}
+
+	if (len(*waits) != 2) || ((*waits)[0] != time.Second) || ((*waits)[1] != 3 * time.Second) {
+		t.Errorf("Fail: %v\n", *waits)
+		return
+	}
+}
+
+func TestRetryGiveUp(t *testing.T){
+	f := &fakeClient{
+		responses: []fakeResponse{ { code: http.StatusTooManyRequests } },
+	}
+	c, _ := newRetryClient(f, RetryPolicy{ MaxAttempts: 2 })
+
+	res, err := c.Get("http://example.com/", nil)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if res.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Fail: %d\n", res.StatusCode)
+		return
+	}
+	if len(f.requests) != 2 {
+		t.Errorf("Fail: %d\n", len(f.requests))
+		return
+	}
+
+	f = &fakeClient{
+		responses: []fakeResponse{ { err: errors.New("network error") } },
+	}
+	c, _ = newRetryClient(f, RetryPolicy{ MaxAttempts: 3 })
+	if _, err := c.Get("http://example.com/", nil); err == nil {
+		t.Errorf("Must Fail\n")
+		return
+	}
+	if len(f.requests) != 3 {
+		t.Errorf("Fail: %d\n", len(f.requests))
+		return
+	}
+}
+
+func TestRetryIdempotent(t *testing.T){
+	f := &fakeClient{
+		responses: []fakeResponse{ { code: http.StatusServiceUnavailable } },
+	}
+	c, _ := newRetryClient(f, RetryPolicy{ MaxAttempts: 3 })
+
+	if _, err := c.Post("http://example.com/", "a", nil); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if len(f.requests) != 1 {
+		t.Errorf("POST must not be retried: %d\n", len(f.requests))
+		return
+	}
+
+	f.requests = nil
+	c, _ = newRetryClient(f, RetryPolicy{ MaxAttempts: 3, RetryNonIdempotent: true })
+	if _, err := c.Post("http://example.com/", "a", nil); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if len(f.requests) != 3 {
+		t.Errorf("Fail: %d\n", len(f.requests))
+		return
+	}
+}
+
+func TestRetryAfter(t *testing.T){
+	f := &fakeClient{
+		responses: []fakeResponse{
+			{
+				code: http.StatusTooManyRequests,
+				header: http.Header{ "Retry-After": []string{"5"} },
+			},
+			{ code: http.StatusOK },
+		},
+	}
+	c, waits := newRetryClient(f, RetryPolicy{
+		MaxAttempts: 3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff: 10 * time.Second,
+	})
+
+	if _, err := c.Get("http://example.com/", nil); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+
+	if (len(*waits) != 1) || ((*waits)[0] != 5 * time.Second) {
+		t.Errorf("Fail: %v\n", *waits)
+		return
+	}
+}
+
+func TestRetryAfterExceedsMaxBackoff(t *testing.T){
+	f := &fakeClient{
+		responses: []fakeResponse{
+			{
+				code: http.StatusServiceUnavailable,
+				header: http.Header{ "Retry-After": []string{"100"} },
+			},
+			{ code: http.StatusOK },
+		},
+	}
+	c, waits := newRetryClient(f, RetryPolicy{
+		MaxAttempts: 3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff: 10 * time.Second,
+	})
+
+	// Retry before Retry-After is not allowed, so that the last response is returned.
+	resp, err := c.Get("http://example.com/", nil)
+	if err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Fail: %d\n", resp.StatusCode)
+		return
+	}
+	if (len(f.requests) != 1) || (len(*waits) != 0) {
+		t.Errorf("Fail: %d, %v\n", len(f.requests), *waits)
+		return
+	}
+}
+
+func TestRetryCloseResponseWithError(t *testing.T){
+	closed := 0
+	attempt := 0
+	do := DoFunc(func(req *http.Request) (*http.Response, error) {
+		attempt += 1
+		res := &http.Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header{},
+			Body: closeCounter{ Reader: strings.NewReader("1"), n: &closed },
+			Request: req,
+		}
+		if attempt == 1 {
+			return res, errors.New("network error")
+		}
+		return res, nil
+	})
+
+	c := NewClient(do, json.Encoder{}, json.Decoder{}, WithRetry(RetryPolicy{ MaxAttempts: 2 }))
+	c.sleep = func(_ context.Context, _ time.Duration) error { return nil }
+
+	var v int
+	if _, err := c.Get("http://example.com/", &v); err != nil {
+		t.Errorf("Fail: %v\n", err)
+		return
+	}
+	if closed != 2 {
+		t.Errorf("Body must be closed: %d\n", closed)
+		return
+	}
+}
+
+func TestRetryContext(t *testing.T){
+	f := &fakeClient{
+		responses: []fakeResponse{ { code: http.StatusServiceUnavailable } },
+	}
+	c := NewClient(f, json.Encoder{}, json.Decoder{}, WithRetry(RetryPolicy{
+		MaxAttempts: 3,
+		InitialBackoff: time.Hour,
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10 * time.Millisecond)
+	defer cancel()
+
+	_, err := c.GetWithContext(ctx, "http://example.com/", nil)
+	if err == nil {
+		t.Errorf("Must Fail\n")
+		return
+	}
+	if len(f.requests) != 1 {
+		t.Errorf("Fail: %d\n", len(f.requests))
+		return
+	}
+}
+
+func TestBackoffJitter(t *testing.T){
+	p := RetryPolicy{ InitialBackoff: time.Second, Jitter: 0.5 }
+	for i := 0; i < 100; i++ {
+		d, _ := p.backoff(nil, 2)
+		if (d < time.Second) || (d > 2 * time.Second) {
+			t.Errorf("Fail: %v\n", d)
+			return
+		}
+	}
+}
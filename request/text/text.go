@@ -0,0 +1,17 @@
+package text
+
+import (
+	"github.com/ymd-h/go/encoding/text"
+)
+
+type (
+	Encoder struct {
+		text.Encoder
+	}
+	Decoder = text.Decoder
+)
+
+
+func (_ Encoder) ContentType() string {
+	return text.MediaType + "; charset=utf-8"
+}